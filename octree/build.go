@@ -0,0 +1,219 @@
+package octree
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNoPoints There were no points to build a tree from.
+var ErrNoPoints = errors.New("octree: no points")
+
+// BuildOctree Makes a new octree holding each of the elements at the point
+// with the same index, bounded by the box around the points. The tree is the
+// same as the one made by adding them one at a time with Add, in order, but
+// it is built several times faster; rather than walking from the root for each
+// point and repeatedly subdividing leaves, the points are sorted into Morton
+// (Z-order) order an octant at a time, with each node made once. Returns a
+// *PointError wrapping ErrInvalidPoint if a point can't be held, or ErrNoPoints.
+func BuildOctree[T any](points []Vector3f, elements []T, opts ...Option) (*Octree[T], error) {
+	if len(points) != len(elements) {
+		return nil, fmt.Errorf("octree: %d points given for %d elements", len(points), len(elements))
+	}
+
+	if len(points) == 0 {
+		return nil, ErrNoPoints
+	}
+
+	min, max := points[0], points[0]
+	for i := range points {
+		if err := checkPoint(&points[i]); err != nil {
+			return nil, err
+		}
+		min = min.Min(&points[i])
+		max = max.Max(&points[i])
+	}
+
+	o := CreateOctree[T](min, max, opts...)
+
+	items := make([]item, len(points))
+	for i := range points {
+		items[i] = item{point: points[i], index: i}
+	}
+
+	b := builder[T]{
+		elements: elements,
+		entries:  make([]entry[T], 0, len(points)),
+		held:     make([]T, len(points)),
+		counts:   make([]int, len(points)),
+		which:    make([]int, len(points)),
+		octants:  make([]uint8, len(points)),
+	}
+	o.root.build(o, &b, 0, items, make([]item, len(items)))
+
+	return o, nil
+}
+
+// item A point to build a tree from and the index of its element.
+type item struct {
+	point Vector3f
+	index int
+}
+
+// builder Holds the elements a tree is being built from, the arrays
+// that the entries of its leaves and their elements are sliced from,
+// and space for counting the elements at each point.
+type builder[T any] struct {
+	elements []T
+	entries  []entry[T]
+	held     []T
+	counts   []int
+	which    []int
+	octants  []uint8
+}
+
+func (n *Node[T]) build(o *Octree[T], b *builder[T], depth int, items, scratch []item) {
+	// make this node hold the items, subdividing it when adding
+	// them one at a time would have; when it would hold more
+	// distinct points than its capacity. scratch is as long as
+	// items, and the two swap roles at each level.
+
+	if len(items) <= o.capacity || !o.canSubdivide(n, depth) || distinct(items, o.capacity+1) <= o.capacity {
+		n.hold(b, items)
+		return
+	}
+
+	n.hasChildren = true
+	subBoxes := n.box.makeSubBoxes()
+	center := n.box.min.Lerp(&n.box.max, 0.5)
+
+	// a stable partition of the items between the octants;
+	// one step of sorting them by their Morton codes.
+	var offsets [9]int
+	octants := b.octants[:len(items)]
+	for i := range items {
+		octants[i] = octant(&items[i].point, &center)
+		offsets[octants[i]+1]++
+	}
+	for i := 1; i < 9; i++ {
+		offsets[i] += offsets[i-1]
+	}
+
+	next := offsets
+	for i := range items {
+		k := octants[i]
+		scratch[next[k]] = items[i]
+		next[k]++
+	}
+
+	// allocate the children together
+	children := make([]Node[T], 8)
+	n.children = make([]*Node[T], 8)
+	for i := range children {
+		child := &children[i]
+		*child = Node[T]{box: subBoxes[i], parent: n, gen: o.gen}
+		n.children[i] = child
+		child.build(o, b, depth+1, scratch[offsets[i]:offsets[i+1]], items[offsets[i]:offsets[i+1]])
+		n.count += child.count
+	}
+}
+
+func (n *Node[T]) hold(b *builder[T], items []item) {
+	// make this leaf hold the items, gathering the elements at
+	// each distinct point in the order they were given.
+
+	if len(items) == 0 {
+		return
+	}
+
+	start := len(b.entries)
+	var index map[Vector3f]int
+	if len(items) > 16 {
+		index = make(map[Vector3f]int)
+	}
+
+	// find the entry for each item, counting the elements at each
+	for i := range items {
+		j, ok := -1, false
+		if index != nil {
+			j, ok = index[items[i].point]
+		} else {
+			for k := start; k < len(b.entries) && !ok; k++ {
+				j, ok = k, b.entries[k].point == items[i].point
+			}
+		}
+
+		if !ok {
+			j = len(b.entries)
+			b.entries = append(b.entries, entry[T]{point: items[i].point})
+			b.counts[j] = 0
+			if index != nil {
+				index[items[i].point] = j
+			}
+		}
+
+		b.which[i] = j
+		b.counts[j]++
+	}
+
+	// hand each entry its part of the elements, limiting the capacity
+	// so that appending to one can't overwrite those of another
+	for j := start; j < len(b.entries); j++ {
+		b.entries[j].elements = b.held[:0:b.counts[j]]
+		b.held = b.held[b.counts[j]:]
+	}
+
+	for i := range items {
+		j := b.which[i]
+		b.entries[j].elements = append(b.entries[j].elements, b.elements[items[i].index])
+	}
+
+	n.entries = b.entries[start:len(b.entries):len(b.entries)]
+	n.count = len(n.entries)
+}
+
+func distinct(items []item, limit int) int {
+	// the number of distinct points among the items, up to limit
+	var buf [16]Vector3f
+	seen := buf[:0]
+	var index map[Vector3f]bool
+	if limit > 16 {
+		index = make(map[Vector3f]bool)
+	}
+
+	count := 0
+	for i := 0; i < len(items) && count < limit; i++ {
+		found := false
+		if index != nil {
+			found = index[items[i].point]
+			index[items[i].point] = true
+		} else {
+			for _, point := range seen {
+				if point == items[i].point {
+					found = true
+					break
+				}
+			}
+			if !found {
+				seen = append(seen, items[i].point)
+			}
+		}
+
+		if !found {
+			count++
+		}
+	}
+
+	return count
+}
+
+func octant(point, center *Vector3f) uint8 {
+	// the index of the first child containing the point; points
+	// on the faces between children belong to the lower one.
+	var index uint8
+	for i := 0; i < 3; i++ {
+		if point[i] > center[i] {
+			index |= 1 << i
+		}
+	}
+	return index
+}
@@ -0,0 +1,78 @@
+package octree
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func sameNodes[T any](tb testing.TB, exp, act *Node[T]) {
+	equals(tb, exp.box, act.box)
+	equals(tb, exp.entries, act.entries)
+	equals(tb, exp.count, act.count)
+	equals(tb, exp.hasChildren, act.hasChildren)
+	equals(tb, len(exp.children), len(act.children))
+	for i := range exp.children {
+		equals(tb, act, act.children[i].parent)
+		sameNodes(tb, exp.children[i], act.children[i])
+	}
+}
+
+func TestBuildMatchesAdd(t *testing.T) {
+	r := rand.New(rand.NewSource(15))
+
+	for _, opts := range [][]Option{
+		nil,
+		{WithCapacity(4)},
+		{WithCapacity(3), WithMaxDepth(3)},
+	} {
+		// snap to a grid so points often coincide and land on octant faces
+		points := make([]Vector3f, 2000)
+		elements := make([]int, len(points))
+		for i := range points {
+			points[i] = Vector3f{float64(r.Intn(17)) / 16, float64(r.Intn(33)) / 16, float64(r.Intn(17)) / 16}
+			elements[i] = i
+		}
+
+		built, err := BuildOctree(points, elements, opts...)
+		equals(t, nil, err)
+
+		added := CreateOctree[int](built.root.box.min, built.root.box.max, opts...)
+		for i := range points {
+			added.Add(elements[i], points[i])
+		}
+
+		sameNodes(t, added.root, built.root)
+
+		// and it can be modified as usual
+		equals(t, true, built.Remove(7))
+		equals(t, false, built.Add(2000, Vector3f{0.5, 0.5, 0.5}) == nil)
+		checkCounts(t, built.root)
+	}
+}
+
+func TestBuildFlatPoints(t *testing.T) {
+	points := []Vector3f{{0, 0, 1}, {1, 0, 1}, {0.5, 0, 1}, {0.5, 0, 1}, {0.25, 0, 1}}
+	built, err := BuildOctree(points, []string{"a", "b", "c", "d", "e"})
+	equals(t, nil, err)
+	equals(t, Box{Vector3f{0, 0, 1}, Vector3f{1, 0, 1}}, built.root.box)
+	equals(t, []string{"c", "d"}, built.ElementsAt(Vector3f{0.5, 0, 1}))
+
+	added := CreateOctree[string](Vector3f{0, 0, 1}, Vector3f{1, 0, 1})
+	for i, e := range []string{"a", "b", "c", "d", "e"} {
+		added.Add(e, points[i])
+	}
+	sameNodes(t, added.root, built.root)
+}
+
+func TestBuildReportsErrors(t *testing.T) {
+	_, err := BuildOctree[int](nil, nil)
+	equals(t, ErrNoPoints, err)
+
+	_, err = BuildOctree([]Vector3f{{0, 0, 0}}, []int{1, 2})
+	equals(t, true, err != nil)
+
+	_, err = BuildOctree([]Vector3f{{0, 0, 0}, {math.NaN(), 0, 0}}, []int{1, 2})
+	equals(t, true, errors.Is(err, ErrInvalidPoint))
+}
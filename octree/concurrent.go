@@ -0,0 +1,200 @@
+package octree
+
+import (
+	"io"
+	"sync"
+)
+
+// ConcurrentOctree An octree that is safe to use from multiple goroutines.
+// Queries hold a read lock, so can run in parallel with one another, while
+// changes to the tree hold a write lock. Slices returned by queries are never
+// shared with the tree.
+//
+// The *Node handles returned by Add, Move and their variants may be passed back
+// to RemoveUsing, Move and MoveBox at any time, but they are part of the tree:
+// their accessors (Parent, Children, Elements, Bounds and so on) read it without
+// any lock, so may only be called inside Read or Write.
+type ConcurrentOctree[T any] struct {
+	mu   sync.RWMutex
+	tree *Octree[T]
+}
+
+// CreateConcurrentOctree Makes a new concurrency-safe octree with the given min
+// and max. See CreateOctree for the available options.
+func CreateConcurrentOctree[T any](min, max Vector3f, opts ...Option) *ConcurrentOctree[T] {
+	return &ConcurrentOctree[T]{tree: CreateOctree[T](min, max, opts...)}
+}
+
+// Clear See Octree.Clear.
+func (c *ConcurrentOctree[T]) Clear() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tree.Clear()
+}
+
+// Add See Octree.Add.
+func (c *ConcurrentOctree[T]) Add(element T, point Vector3f) *Node[T] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tree.Add(element, point)
+}
+
+// AddE See Octree.AddE.
+func (c *ConcurrentOctree[T]) AddE(element T, point Vector3f) (*Node[T], error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tree.AddE(element, point)
+}
+
+// AddBox See Octree.AddBox.
+func (c *ConcurrentOctree[T]) AddBox(element T, box Box) *Node[T] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tree.AddBox(element, box)
+}
+
+// AddBoxE See Octree.AddBoxE.
+func (c *ConcurrentOctree[T]) AddBoxE(element T, box Box) (*Node[T], error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tree.AddBoxE(element, box)
+}
+
+// ElementsAt See Octree.ElementsAt.
+func (c *ConcurrentOctree[T]) ElementsAt(point Vector3f) []T {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	// copy, as the tree's own slice changes with later removals
+	return append([]T(nil), c.tree.ElementsAt(point)...)
+}
+
+// ElementsIn See Octree.ElementsIn.
+func (c *ConcurrentOctree[T]) ElementsIn(box Box) []T {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.tree.ElementsIn(box)
+}
+
+// ElementsWithin See Octree.ElementsWithin.
+func (c *ConcurrentOctree[T]) ElementsWithin(center Vector3f, radius float64) []T {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.tree.ElementsWithin(center, radius)
+}
+
+// ElementsContaining See Octree.ElementsContaining.
+func (c *ConcurrentOctree[T]) ElementsContaining(point Vector3f) []T {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.tree.ElementsContaining(point)
+}
+
+// Nearest See Octree.Nearest.
+func (c *ConcurrentOctree[T]) Nearest(point Vector3f, k int) []Neighbor[T] {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.tree.Nearest(point, k)
+}
+
+// Raycast See Octree.Raycast.
+func (c *ConcurrentOctree[T]) Raycast(origin, dir Vector3f, maxDist, tolerance float64) []RayHit[T] {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.tree.Raycast(origin, dir, maxDist, tolerance)
+}
+
+// RaycastFirst See Octree.RaycastFirst.
+func (c *ConcurrentOctree[T]) RaycastFirst(origin, dir Vector3f, maxDist, tolerance float64) (RayHit[T], bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.tree.RaycastFirst(origin, dir, maxDist, tolerance)
+}
+
+// Remove See Octree.Remove.
+func (c *ConcurrentOctree[T]) Remove(element T) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tree.Remove(element)
+}
+
+// RemoveUsing See Octree.RemoveUsing.
+func (c *ConcurrentOctree[T]) RemoveUsing(element T, node *Node[T]) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tree.RemoveUsing(element, node)
+}
+
+// Move See Octree.Move.
+func (c *ConcurrentOctree[T]) Move(element T, node *Node[T], point Vector3f) *Node[T] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tree.Move(element, node, point)
+}
+
+// MoveBox See Octree.MoveBox.
+func (c *ConcurrentOctree[T]) MoveBox(element T, node *Node[T], box Box) *Node[T] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tree.MoveBox(element, node, box)
+}
+
+// Snapshot See Octree.Snapshot. The snapshot is a plain Octree that can be read
+// without taking any lock while writers carry on modifying this tree.
+func (c *ConcurrentOctree[T]) Snapshot() *Octree[T] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tree.Snapshot()
+}
+
+// WriteTo See Octree.WriteTo.
+func (c *ConcurrentOctree[T]) WriteTo(w io.Writer) (int64, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.tree.WriteTo(w)
+}
+
+// ReadFrom See Octree.ReadFrom.
+func (c *ConcurrentOctree[T]) ReadFrom(r io.Reader) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tree.ReadFrom(r)
+}
+
+// MarshalJSON See Octree.MarshalJSON.
+func (c *ConcurrentOctree[T]) MarshalJSON() ([]byte, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.tree.MarshalJSON()
+}
+
+// UnmarshalJSON See Octree.UnmarshalJSON. The zero ConcurrentOctree can't be used.
+func (c *ConcurrentOctree[T]) UnmarshalJSON(data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tree.UnmarshalJSON(data)
+}
+
+// Read Calls fn with the underlying tree while holding the read lock, so that
+// several queries can be made against the same state of the tree, or the nodes
+// returned by Add and Move inspected. fn must not change the tree, nor retain it
+// or the slices returned by ElementsAt.
+func (c *ConcurrentOctree[T]) Read(fn func(o *Octree[T])) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	fn(c.tree)
+}
+
+// Write Calls fn with the underlying tree while holding the write lock, so
+// that several changes can be made to the tree at once. fn must not retain the tree.
+func (c *ConcurrentOctree[T]) Write(fn func(o *Octree[T])) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fn(c.tree)
+}
+
+// ToString See Octree.ToString.
+func (c *ConcurrentOctree[T]) ToString() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.tree.ToString()
+}
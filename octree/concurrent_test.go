@@ -0,0 +1,86 @@
+package octree
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+func TestConcurrentOctree(t *testing.T) {
+	c := CreateConcurrentOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1}, WithCapacity(4))
+
+	node := c.Add(1, Vector3f{0.1, 0.1, 0.1})
+	c.Add(2, Vector3f{0.1, 0.1, 0.1})
+	c.AddBox(3, Box{Vector3f{0.2, 0.2, 0.2}, Vector3f{0.3, 0.3, 0.3}})
+
+	at := c.ElementsAt(Vector3f{0.1, 0.1, 0.1})
+	equals(t, []int{1, 2}, at)
+	equals(t, true, c.RemoveUsing(1, node))
+	// returned slices are not changed by the tree
+	equals(t, []int{1, 2}, at)
+
+	equals(t, []int{2, 3}, sorted(c.ElementsIn(Box{Vector3f{0, 0, 0}, Vector3f{1, 1, 1}})))
+	equals(t, []int{3}, c.ElementsContaining(Vector3f{0.25, 0.25, 0.25}))
+
+	c.Write(func(o *Octree[int]) {
+		o.Add(4, Vector3f{0.9, 0.9, 0.9})
+		o.Add(5, Vector3f{0.8, 0.8, 0.8})
+	})
+	c.Read(func(o *Octree[int]) {
+		equals(t, 4, len(o.ElementsIn(Box{Vector3f{0, 0, 0}, Vector3f{1, 1, 1}})))
+	})
+
+	equals(t, true, c.Clear())
+	equals(t, 0, len(c.ElementsIn(Box{Vector3f{0, 0, 0}, Vector3f{1, 1, 1}})))
+}
+
+func TestConcurrentOctreeUnderLoad(t *testing.T) {
+	c := CreateConcurrentOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1}, WithCapacity(4))
+	const writers, readers, perWriter = 4, 4, 500
+
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(int64(w)))
+			nodes := make([]*Node[int], perWriter)
+			for i := range nodes {
+				element := w*perWriter + i
+				nodes[i] = c.Add(element, Vector3f{r.Float64(), r.Float64(), r.Float64()})
+				if i%3 == 0 {
+					nodes[i] = c.Move(element, nodes[i], Vector3f{r.Float64(), r.Float64(), r.Float64()})
+				}
+			}
+			// remove every other element this writer added
+			for i := 0; i < perWriter; i += 2 {
+				if !c.RemoveUsing(w*perWriter+i, nodes[i]) {
+					t.Errorf("element %d not removed", w*perWriter+i)
+				}
+			}
+		}(w)
+	}
+
+	for q := 0; q < readers; q++ {
+		wg.Add(1)
+		go func(q int) {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(int64(100 + q)))
+			for i := 0; i < 300; i++ {
+				point := Vector3f{r.Float64(), r.Float64(), r.Float64()}
+				c.ElementsIn(Box{point, Vector3f{point[0] + 0.2, point[1] + 0.2, point[2] + 0.2}})
+				c.ElementsWithin(point, 0.1)
+				c.Nearest(point, 3)
+				c.ElementsAt(point)
+			}
+		}(q)
+	}
+
+	wg.Wait()
+
+	all := c.ElementsIn(Box{Vector3f{0, 0, 0}, Vector3f{1, 1, 1}})
+	equals(t, writers*perWriter/2, len(all))
+	c.Read(func(o *Octree[int]) {
+		checkCounts(t, o.root)
+	})
+}
@@ -0,0 +1,485 @@
+package octree
+
+import (
+	"bufio"
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"reflect"
+)
+
+var (
+	// ErrFormat The data read isn't a tree written by WriteTo, or was
+	// written by a newer version of this package.
+	ErrFormat = errors.New("octree: invalid format")
+	// ErrChecksum The data read doesn't match the checksum written with it.
+	ErrChecksum = errors.New("octree: checksum mismatch")
+	// ErrNoCodec The elements of the tree can't be written or read without
+	// a codec; see WithCodec.
+	ErrNoCodec = errors.New("octree: no codec for elements")
+)
+
+// the format starts with a magic number and version, followed by the
+// settings of the tree, the box of its root and its nodes, depth first,
+// in little endian order. It ends with a CRC-32C checksum of the rest.
+var formatMagic = [4]byte{'O', 'C', 'T', 'R'}
+
+const formatVersion = 1
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// Codec Encodes and decodes the elements of a tree for WriteTo and ReadFrom.
+type Codec[T any] interface {
+	MarshalElement(element T) ([]byte, error)
+	UnmarshalElement(data []byte) (T, error)
+}
+
+// WithCodec Sets the codec used by WriteTo and ReadFrom for the elements of
+// the tree. Without one, integers, strings, byte slices, other values with a
+// fixed size (see encoding/binary) and types implementing both
+// encoding.BinaryMarshaler and (with a pointer receiver)
+// encoding.BinaryUnmarshaler can be written and read. As Option is not
+// generic, the type of codec is only checked when the tree is created:
+// CreateOctree panics unless it is a Codec[T].
+func WithCodec[T any](codec Codec[T]) Option {
+	return func(opts *options) {
+		opts.codec = codec
+	}
+}
+
+// WriteTo Writes the tree to w in a compact binary format, returning the
+// number of bytes written. The format holds the tree's node structure, bounds,
+// points and elements, along with its capacity, maximum depth, growth and
+// looseness, so that ReadFrom gives back the same tree.
+func (o *Octree[T]) WriteTo(w io.Writer) (int64, error) {
+	if o.codec == nil {
+		return 0, ErrNoCodec
+	}
+
+	e := encoder{w: w}
+	e.buf = append(e.buf, formatMagic[:]...)
+	e.buf = append(e.buf, formatVersion)
+	e.uvarint(uint64(o.capacity))
+	e.uvarint(uint64(o.maxDepth))
+	e.boolean(o.growth)
+	e.float(o.looseness)
+	e.vector(&o.root.box.min)
+	e.vector(&o.root.box.max)
+
+	o.root.encode(o, &e)
+
+	e.flush()
+	if e.err != nil {
+		return e.n, e.err
+	}
+
+	n, err := w.Write(binary.LittleEndian.AppendUint32(nil, e.crc))
+	return e.n + int64(n), err
+}
+
+// ReadFrom Replaces the contents of the tree with a tree written by WriteTo,
+// read from r, returning the number of bytes read. The capacity, maximum
+// depth, growth and looseness of the tree read replace those of this one,
+// while its codec, element comparison and parallelism are kept, or are those
+// of CreateOctree when the tree is the zero value. Returns an error wrapping
+// ErrFormat, ErrChecksum or ErrCorrupt, leaving the tree unchanged, when the
+// data isn't a valid tree. Unless r is an io.ByteReader, data past the end of
+// the tree may be read from it.
+func (o *Octree[T]) ReadFrom(r io.Reader) (int64, error) {
+	t := *o
+	if t.codec == nil {
+		t.codec = defaultCodec[T]()
+		if t.codec == nil {
+			return 0, ErrNoCodec
+		}
+	}
+	if t.equals == nil {
+		t.equals = defaultEquals[T]()
+	}
+
+	br, ok := r.(byteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	d := decoder{r: br}
+
+	var magic [5]byte
+	d.read(magic[:])
+	if d.err == nil && (!bytes.Equal(magic[:4], formatMagic[:]) || magic[4] != formatVersion) {
+		return d.n, fmt.Errorf("%w: unknown header %q", ErrFormat, magic[:])
+	}
+
+	t.gen = nextGeneration()
+	t.capacity = int(d.uvarint())
+	t.maxDepth = int(d.uvarint())
+	t.growth = d.boolean()
+	t.looseness = d.float()
+
+	var box Box
+	d.vector(&box.min)
+	d.vector(&box.max)
+
+	if d.err == nil && (t.capacity < 1 || t.maxDepth < 0 || !(t.looseness >= 1) || math.IsInf(t.looseness, 0) ||
+		checkPoint(&box.min) != nil || checkPoint(&box.max) != nil || box != NewBox(box.min, box.max)) {
+		return d.n, fmt.Errorf("%w: invalid settings", ErrFormat)
+	}
+
+	t.root = &Node[T]{box: box, gen: t.gen}
+	t.root.decode(&t, &d, 0)
+
+	// the checksum isn't part of what it checks
+	crc := d.crc
+	var sum [4]byte
+	d.read(sum[:])
+
+	if d.err != nil {
+		return d.n, d.err
+	}
+
+	if binary.LittleEndian.Uint32(sum[:]) != crc {
+		return d.n, ErrChecksum
+	}
+
+	if err := t.Validate(); err != nil {
+		return d.n, err
+	}
+
+	*o = t
+	return d.n, nil
+}
+
+func (n *Node[T]) encode(o *Octree[T], e *encoder) {
+	e.boolean(n.hasChildren)
+
+	e.uvarint(uint64(len(n.volumes)))
+	for i := range n.volumes {
+		e.vector(&n.volumes[i].box.min)
+		e.vector(&n.volumes[i].box.max)
+		o.encodeElement(e, n.volumes[i].element)
+	}
+
+	if n.hasChildren {
+		// the boxes of the children follow from that of their parent
+		for _, child := range n.children {
+			child.encode(o, e)
+		}
+		return
+	}
+
+	e.uvarint(uint64(len(n.entries)))
+	for i := range n.entries {
+		e.vector(&n.entries[i].point)
+		e.uvarint(uint64(len(n.entries[i].elements)))
+		for _, element := range n.entries[i].elements {
+			o.encodeElement(e, element)
+		}
+	}
+}
+
+func (n *Node[T]) decode(o *Octree[T], d *decoder, depth int) {
+	// read the contents of the node, whose box is already
+	// known, and its descendants, counting what they hold.
+
+	hasChildren := d.boolean()
+
+	for i := d.uvarint(); i > 0 && d.err == nil; i-- {
+		var v volume[T]
+		d.vector(&v.box.min)
+		d.vector(&v.box.max)
+		if d.err == nil && v.box != NewBox(v.box.min, v.box.max) {
+			d.fail("inverted volume box %v", v.box.ToString())
+		}
+		v.element = o.decodeElement(d)
+		n.volumes = append(n.volumes, v)
+	}
+	n.count = len(n.volumes)
+
+	if d.err != nil {
+		return
+	}
+
+	if hasChildren {
+		if !o.canSubdivide(n, depth) {
+			// also bounds how deep corrupt data can make the tree
+			d.fail("branch at depth %d can't be subdivided", depth)
+			return
+		}
+
+		n.hasChildren = true
+		subBoxes := n.box.makeSubBoxes()
+		for i := 0; i < 8 && d.err == nil; i++ {
+			child := &Node[T]{box: subBoxes[i], parent: n, gen: o.gen}
+			n.children = append(n.children, child)
+			child.decode(o, d, depth+1)
+			n.count += child.count
+		}
+		return
+	}
+
+	for i := d.uvarint(); i > 0 && d.err == nil; i-- {
+		var e entry[T]
+		d.vector(&e.point)
+		for j := d.uvarint(); j > 0 && d.err == nil; j-- {
+			e.elements = append(e.elements, o.decodeElement(d))
+		}
+		n.entries = append(n.entries, e)
+	}
+	n.count += len(n.entries)
+}
+
+// encoder Buffers what is written to w, keeping a checksum of it.
+type encoder struct {
+	w   io.Writer
+	buf []byte
+	crc uint32
+	n   int64
+	err error
+}
+
+func (e *encoder) flush() {
+	if e.err == nil {
+		e.crc = crc32.Update(e.crc, crcTable, e.buf)
+		var n int
+		n, e.err = e.w.Write(e.buf)
+		e.n += int64(n)
+	}
+	e.buf = e.buf[:0]
+}
+
+func (e *encoder) uvarint(v uint64) {
+	if len(e.buf) >= 1<<16 {
+		e.flush()
+	}
+	e.buf = binary.AppendUvarint(e.buf, v)
+}
+
+func (e *encoder) boolean(b bool) {
+	if b {
+		e.uvarint(1)
+	} else {
+		e.uvarint(0)
+	}
+}
+
+func (e *encoder) float(f float64) {
+	e.buf = binary.LittleEndian.AppendUint64(e.buf, math.Float64bits(f))
+}
+
+func (e *encoder) vector(v *Vector3f) {
+	for i := 0; i < 3; i++ {
+		e.float(v[i])
+	}
+}
+
+func (e *encoder) bytes(data []byte) {
+	e.uvarint(uint64(len(data)))
+	e.buf = append(e.buf, data...)
+}
+
+// byteReader Is what a decoder reads from.
+type byteReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+// decoder Reads from r, keeping a checksum of what it has read.
+// Once it fails, it reads nothing more and returns zero values.
+type decoder struct {
+	r   byteReader
+	crc uint32
+	n   int64
+	err error
+}
+
+func (d *decoder) fail(format string, args ...interface{}) {
+	if d.err == nil {
+		d.err = fmt.Errorf("%w: %v", ErrFormat, fmt.Sprintf(format, args...))
+	}
+}
+
+func (d *decoder) read(p []byte) {
+	if d.err != nil {
+		clear(p)
+		return
+	}
+
+	n, err := io.ReadFull(d.r, p)
+	d.n += int64(n)
+	d.crc = crc32.Update(d.crc, crcTable, p[:n])
+	if err != nil {
+		d.fail("%v", err)
+	}
+}
+
+func (d *decoder) ReadByte() (byte, error) {
+	var b [1]byte
+	d.read(b[:])
+	return b[0], d.err
+}
+
+func (d *decoder) uvarint() uint64 {
+	v, err := binary.ReadUvarint(d)
+	if err != nil {
+		d.fail("%v", err)
+		return 0
+	}
+	return v
+}
+
+func (d *decoder) boolean() bool {
+	v := d.uvarint()
+	if v > 1 {
+		d.fail("invalid flag %d", v)
+	}
+	return v == 1
+}
+
+func (d *decoder) float() float64 {
+	var b [8]byte
+	d.read(b[:])
+	return math.Float64frombits(binary.LittleEndian.Uint64(b[:]))
+}
+
+func (d *decoder) vector(v *Vector3f) {
+	for i := 0; i < 3; i++ {
+		v[i] = d.float()
+	}
+}
+
+func (d *decoder) bytes() []byte {
+	// read in chunks, so that a corrupt length
+	// can't allocate more than the data holds
+	length := d.uvarint()
+	data := []byte{}
+	for uint64(len(data)) < length && d.err == nil {
+		chunk := min(length-uint64(len(data)), 1<<16)
+		data = append(data, make([]byte, chunk)...)
+		d.read(data[len(data)-int(chunk):])
+	}
+	return data
+}
+
+func (o *Octree[T]) encodeElement(e *encoder, element T) {
+	if e.err != nil {
+		return
+	}
+
+	data, err := o.codec.MarshalElement(element)
+	if err != nil {
+		e.err = fmt.Errorf("octree: encoding element: %w", err)
+		return
+	}
+	e.bytes(data)
+}
+
+func (o *Octree[T]) decodeElement(d *decoder) T {
+	var element T
+	data := d.bytes()
+	if d.err != nil {
+		return element
+	}
+
+	element, err := o.codec.UnmarshalElement(data)
+	if err != nil {
+		d.fail("decoding element: %v", err)
+	}
+	return element
+}
+
+func defaultCodec[T any]() Codec[T] {
+	// the codec for elements of type T when none is given, if any
+	t := reflect.TypeFor[T]()
+	marshaler := reflect.TypeFor[encoding.BinaryMarshaler]()
+	unmarshaler := reflect.TypeFor[encoding.BinaryUnmarshaler]()
+
+	if t.Implements(marshaler) && reflect.PointerTo(t).Implements(unmarshaler) {
+		return marshalerCodec[T]{}
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.String:
+		return valueCodec[T]{}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return valueCodec[T]{}
+		}
+	}
+
+	var zero T
+	if binary.Size(zero) > 0 {
+		return valueCodec[T]{}
+	}
+
+	return nil
+}
+
+// marshalerCodec Encodes elements with their MarshalBinary
+// and UnmarshalBinary methods.
+type marshalerCodec[T any] struct{}
+
+func (marshalerCodec[T]) MarshalElement(element T) ([]byte, error) {
+	return interface{}(element).(encoding.BinaryMarshaler).MarshalBinary()
+}
+
+func (marshalerCodec[T]) UnmarshalElement(data []byte) (T, error) {
+	var element T
+	err := interface{}(&element).(encoding.BinaryUnmarshaler).UnmarshalBinary(data)
+	return element, err
+}
+
+// valueCodec Encodes integers as varints, strings and byte
+// slices as they are, and other fixed size values with
+// encoding/binary, in little endian order.
+type valueCodec[T any] struct{}
+
+func (valueCodec[T]) MarshalElement(element T) ([]byte, error) {
+	v := reflect.ValueOf(&element).Elem()
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return binary.AppendVarint(nil, v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return binary.AppendUvarint(nil, v.Uint()), nil
+	case reflect.String:
+		return []byte(v.String()), nil
+	case reflect.Slice:
+		return v.Bytes(), nil
+	}
+	return binary.Append(nil, binary.LittleEndian, element)
+}
+
+func (valueCodec[T]) UnmarshalElement(data []byte) (T, error) {
+	var element T
+	v := reflect.ValueOf(&element).Elem()
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x, n := binary.Varint(data)
+		if n != len(data) || v.OverflowInt(x) {
+			return element, fmt.Errorf("invalid %v", v.Type())
+		}
+		v.SetInt(x)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		x, n := binary.Uvarint(data)
+		if n != len(data) || v.OverflowUint(x) {
+			return element, fmt.Errorf("invalid %v", v.Type())
+		}
+		v.SetUint(x)
+	case reflect.String:
+		v.SetString(string(data))
+	case reflect.Slice:
+		v.SetBytes(append([]byte{}, data...))
+	default:
+		n, err := binary.Decode(data, binary.LittleEndian, &element)
+		if err == nil && n != len(data) {
+			err = fmt.Errorf("%d bytes left over decoding %v", len(data)-n, v.Type())
+		}
+		return element, err
+	}
+	return element, nil
+}
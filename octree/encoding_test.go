@@ -0,0 +1,254 @@
+package octree
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func sameVolumes[T any](tb testing.TB, exp, act *Octree[T]) {
+	var expBoxes, actBoxes []Box
+	var expElements, actElements []T
+	for box, element := range exp.Volumes() {
+		expBoxes = append(expBoxes, box)
+		expElements = append(expElements, element)
+	}
+	for box, element := range act.Volumes() {
+		actBoxes = append(actBoxes, box)
+		actElements = append(actElements, element)
+	}
+	equals(tb, expBoxes, actBoxes)
+	equals(tb, expElements, actElements)
+}
+
+func TestEncodingRoundTrips(t *testing.T) {
+	r := rand.New(rand.NewSource(20))
+
+	for _, opts := range [][]Option{
+		nil,
+		{WithCapacity(4)},
+		{WithCapacity(3), WithMaxDepth(3), WithLooseness(1.5)},
+		{WithCapacity(2), WithGrowth()},
+	} {
+		o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 2, 1}, opts...)
+		for i := 0; i < 1000; i++ {
+			// snap to a grid so points often coincide and land on octant faces
+			point := Vector3f{float64(r.Intn(17)) / 16, float64(r.Intn(33)) / 16, float64(r.Intn(17)) / 16}
+			o.Add(i-500, point)
+			if i%10 == 0 {
+				corner := point.Plus(&Vector3f{0.1, 0.1, 0.1})
+				o.AddBox(i, NewBox(point, corner.Min(&Vector3f{1, 2, 1})))
+			}
+		}
+		if o.growth {
+			o.Add(1000, Vector3f{3, -1, 2})
+		}
+
+		var buf bytes.Buffer
+		written, err := o.WriteTo(&buf)
+		equals(t, nil, err)
+		equals(t, int64(buf.Len()), written)
+		data := append([]byte{}, buf.Bytes()...)
+
+		// the settings come from the data read
+		read := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+		n, err := read.ReadFrom(&buf)
+		equals(t, nil, err)
+		equals(t, written, n)
+
+		sameNodes(t, o.root, read.root)
+		sameVolumes(t, o, read)
+		equals(t, nil, read.Validate())
+		equals(t, o.capacity, read.capacity)
+		equals(t, o.maxDepth, read.maxDepth)
+		equals(t, o.growth, read.growth)
+		equals(t, o.looseness, read.looseness)
+
+		// and it can be modified as usual
+		equals(t, true, read.Remove(7))
+		equals(t, false, read.Add(2000, Vector3f{0.5, 0.5, 0.5}) == nil)
+		equals(t, nil, read.Validate())
+
+		// as can the zero value, given the settings of CreateOctree
+		var zero Octree[int]
+		_, err = zero.ReadFrom(bytes.NewReader(data))
+		equals(t, nil, err)
+		sameNodes(t, o.root, zero.root)
+		equals(t, true, zero.Remove(7))
+		equals(t, nil, zero.Validate())
+	}
+}
+
+func TestEncodingElements(t *testing.T) {
+	points := []Vector3f{{0, 0, 0}, {1, 1, 1}, {0.25, 0.5, 0.75}, {0.25, 0.5, 0.75}}
+	var buf bytes.Buffer
+
+	strs, err := BuildOctree(points, []string{"a", "", "ccc", "d"})
+	equals(t, nil, err)
+	_, err = strs.WriteTo(&buf)
+	equals(t, nil, err)
+	readStrs := CreateOctree[string](Vector3f{}, Vector3f{})
+	_, err = readStrs.ReadFrom(&buf)
+	equals(t, nil, err)
+	equals(t, []string{"ccc", "d"}, readStrs.ElementsAt(Vector3f{0.25, 0.5, 0.75}))
+	equals(t, []string{""}, readStrs.ElementsAt(Vector3f{1, 1, 1}))
+
+	// fixed size values are encoded with encoding/binary
+	samples, err := BuildOctree(points, []sample{{1, [3]uint8{2, 3, 4}, 5}, {}, {6, [3]uint8{}, -7}, {8, [3]uint8{9}, 10}})
+	equals(t, nil, err)
+	buf.Reset()
+	_, err = samples.WriteTo(&buf)
+	equals(t, nil, err)
+	readSamples := CreateOctree[sample](Vector3f{}, Vector3f{})
+	_, err = readSamples.ReadFrom(&buf)
+	equals(t, nil, err)
+	sameNodes(t, samples.root, readSamples.root)
+
+	// other types need a codec
+	records, err := BuildOctree(points, []record{{"a", nil}, {"b", []string{"x"}}, {"c", nil}, {"d", []string{"y", "z"}}})
+	equals(t, nil, err)
+	buf.Reset()
+	_, err = records.WriteTo(&buf)
+	equals(t, ErrNoCodec, err)
+	equals(t, 0, buf.Len())
+
+	records, err = BuildOctree(points, []record{{"a", nil}, {"b", []string{"x"}}, {"c", nil}, {"d", []string{"y", "z"}}},
+		WithCodec[record](recordCodec{}))
+	equals(t, nil, err)
+	_, err = records.WriteTo(&buf)
+	equals(t, nil, err)
+	readRecords := CreateOctree[record](Vector3f{}, Vector3f{}, WithCodec[record](recordCodec{}))
+	_, err = readRecords.ReadFrom(&buf)
+	equals(t, nil, err)
+	sameNodes(t, records.root, readRecords.root)
+}
+
+func TestEncodingReportsErrors(t *testing.T) {
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1}, WithCapacity(2))
+	for i := 0; i < 50; i++ {
+		o.Add(i, Vector3f{float64(i%5) / 4, float64(i%7) / 6, float64(i%3) / 2})
+	}
+	var buf bytes.Buffer
+	_, err := o.WriteTo(&buf)
+	equals(t, nil, err)
+	data := append([]byte{}, buf.Bytes()...)
+
+	read := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+	read.Add(-1, Vector3f{0.5, 0.5, 0.5})
+	failed := func(err error, target error) {
+		t.Helper()
+		equals(t, true, errors.Is(err, target))
+		// leaving the tree unchanged
+		equals(t, []int{-1}, read.ElementsAt(Vector3f{0.5, 0.5, 0.5}))
+		equals(t, 1, read.root.count)
+	}
+
+	_, err = read.ReadFrom(bytes.NewReader([]byte("OCTR\x02")))
+	failed(err, ErrFormat)
+	_, err = read.ReadFrom(bytes.NewReader([]byte("PLY\n")))
+	failed(err, ErrFormat)
+
+	for _, length := range []int{0, 5, 20, len(data) / 2, len(data) - 1} {
+		_, err = read.ReadFrom(bytes.NewReader(data[:length]))
+		failed(err, ErrFormat)
+	}
+
+	// the low bits of the root's minimum x, and of the checksum
+	for _, i := range []int{16, len(data) - 1} {
+		corrupt := append([]byte{}, data...)
+		corrupt[i] ^= 0x01
+		_, err = read.ReadFrom(bytes.NewReader(corrupt))
+		failed(err, ErrChecksum)
+	}
+
+	// data that is well formed, but isn't a valid tree
+	for n := range o.Nodes() {
+		if len(n.entries) > 0 {
+			n.entries = append(n.entries, n.entries[0])
+			break
+		}
+	}
+	buf.Reset()
+	_, err = o.WriteTo(&buf)
+	equals(t, nil, err)
+	_, err = read.ReadFrom(&buf)
+	failed(err, ErrCorrupt)
+
+	// as are volumes stored with an inverted box
+	inverted := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+	inverted.AddBox(1, NewBox(Vector3f{0.2, 0.2, 0.2}, Vector3f{0.4, 0.4, 0.4}))
+	v := &inverted.root.volumes[0]
+	v.box.min, v.box.max = v.box.max, v.box.min
+	buf.Reset()
+	_, err = inverted.WriteTo(&buf)
+	equals(t, nil, err)
+	_, err = read.ReadFrom(&buf)
+	failed(err, ErrFormat)
+
+	// anything after the tree is left alone
+	next := bytes.NewReader(append(append([]byte{}, data...), "next"...))
+	n, err := read.ReadFrom(next)
+	equals(t, nil, err)
+	equals(t, int64(len(data)), n)
+	equals(t, 4, next.Len())
+}
+
+type sample struct {
+	Intensity uint16
+	Color     [3]uint8
+	Time      float64
+}
+
+type record struct {
+	name string
+	tags []string
+}
+
+type recordCodec struct{}
+
+func (recordCodec) MarshalElement(element record) ([]byte, error) {
+	return []byte(strconv.Quote(element.name) + " " + strconv.Quote(strings.Join(element.tags, ","))), nil
+}
+
+func (recordCodec) UnmarshalElement(data []byte) (record, error) {
+	var element record
+	name, tags, _ := strings.Cut(string(data), " ")
+	var err error
+	if element.name, err = strconv.Unquote(name); err != nil {
+		return element, err
+	}
+	joined, err := strconv.Unquote(tags)
+	if joined != "" {
+		element.tags = strings.Split(joined, ",")
+	}
+	return element, err
+}
+
+func FuzzReadFrom(f *testing.F) {
+	o := CreateOctree[string](Vector3f{0, 0, 0}, Vector3f{1, 1, 1}, WithCapacity(1), WithMaxDepth(4))
+	for i := 0; i < 12; i++ {
+		o.Add(strconv.Itoa(i), Vector3f{float64(i%3) / 2, float64(i%4) / 3, float64(i%5) / 4})
+	}
+	o.AddBox("box", NewBox(Vector3f{0.1, 0.1, 0.1}, Vector3f{0.2, 0.2, 0.2}))
+	var buf bytes.Buffer
+	_, err := o.WriteTo(&buf)
+	equals(f, nil, err)
+	f.Add(buf.Bytes())
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		read := CreateOctree[string](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+		if _, err := read.ReadFrom(bytes.NewReader(data)); err != nil {
+			return
+		}
+
+		// whatever is read is a valid tree, that writes back the same
+		equals(t, nil, read.Validate())
+		var buf bytes.Buffer
+		_, err := read.WriteTo(&buf)
+		equals(t, nil, err)
+		equals(t, true, bytes.HasPrefix(data, buf.Bytes()))
+	})
+}
@@ -0,0 +1,42 @@
+package octree
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	// ErrOutOfBounds The point is outside the bounds of the tree.
+	ErrOutOfBounds = errors.New("octree: point out of bounds")
+	// ErrInvalidPoint The point has a NaN or infinite coordinate.
+	ErrInvalidPoint = errors.New("octree: invalid point")
+	// ErrInternal The tree failed to place a point it should have been able to hold.
+	ErrInternal = errors.New("octree: internal error")
+	// ErrCorrupt The tree's structure is inconsistent; see Octree.Validate.
+	ErrCorrupt = errors.New("octree: corrupt tree")
+)
+
+// PointError Records the point that caused an error. Use errors.Is
+// to check which of the Err values it wraps.
+type PointError struct {
+	Point Vector3f
+	Err   error
+}
+
+func (e *PointError) Error() string {
+	return fmt.Sprintf("%v at %v", e.Err, e.Point.ToString())
+}
+
+func (e *PointError) Unwrap() error {
+	return e.Err
+}
+
+func checkPoint(point *Vector3f) error {
+	for i := 0; i < 3; i++ {
+		if math.IsNaN(point[i]) || math.IsInf(point[i], 0) {
+			return &PointError{Point: *point, Err: ErrInvalidPoint}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package octree
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestAddReportsErrors(t *testing.T) {
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+
+	node, err := o.AddE(1, Vector3f{0.5, 0.5, 0.5})
+	equals(t, nil, err)
+	equals(t, o.root, node)
+
+	node, err = o.AddE(2, Vector3f{1.5, 0.5, 0.5})
+	equals(t, true, node == nil)
+	equals(t, true, errors.Is(err, ErrOutOfBounds))
+	var pointErr *PointError
+	equals(t, true, errors.As(err, &pointErr))
+	equals(t, Vector3f{1.5, 0.5, 0.5}, pointErr.Point)
+	equals(t, "octree: point out of bounds at Vector3f{1.500000, 0.500000, 0.500000}", err.Error())
+
+	_, err = o.AddE(3, Vector3f{math.NaN(), 0.5, 0.5})
+	equals(t, true, errors.Is(err, ErrInvalidPoint))
+	_, err = o.AddE(3, Vector3f{0.5, math.Inf(-1), 0.5})
+	equals(t, true, errors.Is(err, ErrInvalidPoint))
+
+	// growing trees only reject invalid points
+	o = CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1}, WithGrowth())
+	_, err = o.AddE(2, Vector3f{1.5, 0.5, 0.5})
+	equals(t, nil, err)
+	_, err = o.AddE(3, Vector3f{0.5, 0.5, math.Inf(1)})
+	equals(t, true, errors.Is(err, ErrInvalidPoint))
+
+	// unless they can't grow to hold the point, which leaves them unchanged
+	bounds, nodes := o.Root().Bounds(), o.Stats().Nodes
+	_, err = o.AddE(4, Vector3f{math.MaxFloat64, 0.5, 0.5})
+	equals(t, true, errors.Is(err, ErrOutOfBounds))
+	equals(t, bounds, o.Root().Bounds())
+	equals(t, nodes, o.Stats().Nodes)
+
+	equals(t, 1, len(o.ElementsIn(Box{Vector3f{-1e300, -1e300, -1e300}, Vector3f{1e300, 1e300, 1e300}})))
+}
+
+func TestAddReportsInternalErrors(t *testing.T) {
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+	o.Add(1, Vector3f{0.1, 0.1, 0.1})
+	o.Add(2, Vector3f{0.9, 0.9, 0.9})
+
+	// corrupt the tree so that no child contains the point
+	o.root.children[7].box = Box{Vector3f{2, 2, 2}, Vector3f{3, 3, 3}}
+	_, err := o.AddE(3, Vector3f{0.8, 0.8, 0.8})
+	equals(t, true, errors.Is(err, ErrInternal))
+}
@@ -0,0 +1,146 @@
+package octree
+
+// WithGrowth Allows the tree to grow its bounds, rather than rejecting the
+// element, when a point outside of them is added or moved to. Each time the tree
+// grows, a new root twice the size of the old one is created in the direction
+// of the point, with the old root as one of its octants.
+func WithGrowth() Option {
+	return func(opts *options) {
+		opts.growth = true
+	}
+}
+
+func (o *Octree[T]) grow(points ...*Vector3f) error {
+	// grow the root until it contains the points, returning a
+	// *PointError for the first one it can't be grown to contain,
+	// in which case the tree is left unchanged.
+
+	box := o.root.box
+	for _, point := range points {
+		if err := checkPoint(point); err != nil {
+			return err
+		}
+
+		for !box.ContainsPoint(point) {
+			box, _ = grownBox(box, point)
+			if checkPoint(&box.min) != nil || checkPoint(&box.max) != nil {
+				return &PointError{Point: *point, Err: ErrOutOfBounds}
+			}
+		}
+	}
+
+	for _, point := range points {
+		for !o.root.box.ContainsPoint(point) {
+			o.growTowards(point)
+		}
+	}
+
+	return nil
+}
+
+func grownBox(box Box, point *Vector3f) (Box, int) {
+	// the box twice the size of box in the direction of point,
+	// and the octant of it that box is
+
+	size := box.Size()
+	octant := 0
+
+	for i := 0; i < 3; i++ {
+		if size[i] == 0 {
+			size[i] = 1
+		}
+
+		if point[i] < box.min[i] {
+			// box becomes the upper half on this axis
+			box.min[i] -= size[i]
+			octant |= 1 << i
+		} else {
+			box.max[i] += size[i]
+		}
+	}
+
+	return box, octant
+}
+
+func (o *Octree[T]) growTowards(point *Vector3f) {
+	// replace the root with one twice its size in the
+	// direction of point, which grow has checked is possible
+
+	old := o.writable(o.root)
+	box, octant := grownBox(old.box, point)
+
+	root := &Node[T]{box: box, gen: o.gen}
+	subBoxes := box.makeSubBoxes()
+
+	if subBoxes[octant] != old.box || old.holdsStranded(&old.box, &box) ||
+		(o.maxDepth > 0 && old.height() >= o.maxDepth) {
+		// rounding (or a flat box) means the old root doesn't
+		// exactly match any octant, it holds points that now
+		// belong to another octant, or its deepest branches would
+		// be pushed past the maximum depth, so reinsert its contents.
+		held := &Node[T]{}
+		old.detachInto(o, held)
+		old.parent = root
+		o.root = root
+		for i := range held.entries {
+			root.tryAdd(o, 0, held.entries[i].elements, &held.entries[i].point)
+		}
+		for i := range held.volumes {
+			root.tryAddVolume(o, 0, held.volumes[i].element, &held.volumes[i].box)
+		}
+		return
+	}
+
+	root.hasChildren = true
+	root.count = old.count
+	for i := 0; i < 8; i++ {
+		if i == octant {
+			root.children = append(root.children, old)
+		} else {
+			root.children = append(root.children, &Node[T]{box: subBoxes[i], parent: root, gen: o.gen})
+		}
+	}
+
+	old.parent = root
+	o.root = root
+}
+
+func (n *Node[T]) height() int {
+	// the depth of the deepest leaf below n, relative to n
+	height := 0
+	for _, child := range n.children {
+		height = max(height, child.height()+1)
+	}
+	return height
+}
+
+func (n *Node[T]) holdsStranded(old, root *Box) bool {
+	// whether n (or a descendant) holds a point on the minimum faces
+	// of old, the box of the old root, that aren't on those of root.
+	// A search from root would look for them in a preceding octant.
+
+	if n.hasChildren {
+		for _, child := range n.children {
+			if stranded(&child.box.min, old, root) && child.holdsStranded(old, root) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for i := range n.entries {
+		if stranded(&n.entries[i].point, old, root) {
+			return true
+		}
+	}
+	return false
+}
+
+func stranded(point *Vector3f, old, root *Box) bool {
+	for i := 0; i < 3; i++ {
+		if point[i] == old.min[i] && old.min[i] != root.min[i] {
+			return true
+		}
+	}
+	return false
+}
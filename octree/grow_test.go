@@ -0,0 +1,139 @@
+package octree
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestGrowsRoot(t *testing.T) {
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1}, WithGrowth())
+
+	node1 := o.Add(1, Vector3f{0.1, 0.1, 0.1})
+	o.Add(2, Vector3f{0.9, 0.9, 0.9})
+	old := o.root
+
+	// grows up in x, and down in y and z
+	equals(t, false, o.Add(3, Vector3f{1.5, -0.5, -0.5}) == nil)
+	equals(t, Box{Vector3f{0, -1, -1}, Vector3f{2, 1, 1}}, o.root.box)
+	equals(t, old, o.root.children[6])
+	equals(t, o.root, old.parent)
+	equals(t, 3, o.root.count)
+	checkCounts(t, o.root)
+
+	// grows repeatedly to reach far points
+	o.Add(4, Vector3f{-20, 0.5, 0.5})
+	equals(t, true, o.root.box.ContainsPoint(&Vector3f{-20, 0.5, 0.5}))
+	equals(t, true, o.root.box.ContainsPoint(&Vector3f{0, -1, -1}))
+	checkCounts(t, o.root)
+
+	equals(t, []int{1}, o.ElementsAt(Vector3f{0.1, 0.1, 0.1}))
+	equals(t, []int{2}, o.ElementsAt(Vector3f{0.9, 0.9, 0.9}))
+	equals(t, []int{3}, o.ElementsAt(Vector3f{1.5, -0.5, -0.5}))
+	equals(t, []int{4}, o.ElementsAt(Vector3f{-20, 0.5, 0.5}))
+	equals(t, true, o.RemoveUsing(1, node1))
+
+	// moves outside the bounds too
+	node := o.Move(2, o.root, Vector3f{100, 100, 100})
+	equals(t, false, node == nil)
+	equals(t, []int{2}, o.ElementsAt(Vector3f{100, 100, 100}))
+
+	// points that can never be held
+	equals(t, true, o.Add(5, Vector3f{math.NaN(), 0, 0}) == nil)
+	equals(t, true, o.Add(5, Vector3f{0, math.Inf(1), 0}) == nil)
+
+	// not without the option
+	o = CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+	equals(t, true, o.Add(1, Vector3f{1.5, 0, 0}) == nil)
+	equals(t, Box{Vector3f{0, 0, 0}, Vector3f{1, 1, 1}}, o.root.box)
+}
+
+func TestGrowsFlatRoot(t *testing.T) {
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{0, 0, 0}, WithGrowth())
+
+	o.Add(1, Vector3f{0, 0, 0})
+	o.Add(2, Vector3f{3, 0.25, -2})
+	equals(t, []int{1}, o.ElementsAt(Vector3f{0, 0, 0}))
+	equals(t, []int{2}, o.ElementsAt(Vector3f{3, 0.25, -2}))
+	checkCounts(t, o.root)
+}
+
+func TestGrowsPastPointsOnFaces(t *testing.T) {
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1}, WithGrowth())
+
+	node1 := o.Add(1, Vector3f{0, 0.5, 0.5})
+	node2 := o.Add(2, Vector3f{0.5, 0, 0.25})
+	o.Add(3, Vector3f{0.75, 0.75, 0.75})
+
+	// the old root becomes the upper octant in x and y, so
+	// points on its lower faces now belong to other octants
+	o.Add(4, Vector3f{-0.5, -0.5, 0.5})
+	equals(t, []int{1}, o.ElementsAt(Vector3f{0, 0.5, 0.5}))
+	equals(t, []int{2}, o.ElementsAt(Vector3f{0.5, 0, 0.25}))
+	equals(t, []int{3}, o.ElementsAt(Vector3f{0.75, 0.75, 0.75}))
+	checkCounts(t, o.root)
+
+	// coincident points are still held together
+	o.Add(5, Vector3f{0, 0.5, 0.5})
+	equals(t, []int{1, 5}, o.ElementsAt(Vector3f{0, 0.5, 0.5}))
+	equals(t, 4, o.root.count)
+
+	// and nodes from before growing can still be used
+	equals(t, true, o.RemoveUsing(1, node1))
+	equals(t, false, o.Move(2, node2, Vector3f{0.5, 0, 0.5}) == nil)
+	equals(t, []int{2}, o.ElementsAt(Vector3f{0.5, 0, 0.5}))
+}
+
+func TestGrowsWithinMaxDepth(t *testing.T) {
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1}, WithGrowth(), WithMaxDepth(2))
+	o.Add(1, Vector3f{0.1, 0.1, 0.1})
+	o.Add(2, Vector3f{0.2, 0.2, 0.2})
+	o.Add(3, Vector3f{0.9, 0.9, 0.9})
+	equals(t, 2, maxDepth(o.root))
+
+	// the old root's branches would be pushed past the maximum depth
+	o.Add(4, Vector3f{1.5, 1.5, 1.5})
+	equals(t, 2, maxDepth(o.root))
+	equals(t, []int{1}, o.ElementsAt(Vector3f{0.1, 0.1, 0.1}))
+	equals(t, []int{2}, o.ElementsAt(Vector3f{0.2, 0.2, 0.2}))
+	checkCounts(t, o.root)
+}
+
+func TestGrowsOnlyToMoveHeldElements(t *testing.T) {
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1}, WithGrowth())
+	node := o.Add(1, Vector3f{0.5, 0.5, 0.5})
+
+	equals(t, true, o.Move(2, node, Vector3f{1000, 0, 0}) == nil)
+	equals(t, NewBox(Vector3f{0, 0, 0}, Vector3f{1, 1, 1}), o.Root().Bounds())
+
+	equals(t, false, o.Move(1, node, Vector3f{1000, 0, 0}) == nil)
+	equals(t, []int{1}, o.ElementsAt(Vector3f{1000, 0, 0}))
+	equals(t, Vector3f{1024, 1024, 1024}, o.Root().Bounds().Max())
+}
+
+func TestGrowthMatchesBruteForce(t *testing.T) {
+	r := rand.New(rand.NewSource(8))
+	o := CreateOctree[int](Vector3f{0.3, 0.3, 0.3}, Vector3f{0.7, 0.7, 0.7}, WithGrowth(), WithCapacity(3))
+	points := make([]Vector3f, 300)
+	for i := range points {
+		points[i] = Vector3f{r.NormFloat64() * 10, r.NormFloat64() * 10, r.NormFloat64() * 10}
+		equals(t, false, o.Add(i, points[i]) == nil)
+	}
+	checkCounts(t, o.root)
+
+	for i := range points {
+		equals(t, []int{i}, o.ElementsAt(points[i]))
+	}
+
+	box := Box{Vector3f{-5, -5, -5}, Vector3f{5, 5, 5}}
+	exp := []int{}
+	for i := range points {
+		if box.ContainsPoint(&points[i]) {
+			exp = append(exp, i)
+		}
+	}
+	act := o.ElementsIn(box)
+	sort.Ints(act)
+	equals(t, exp, act)
+}
@@ -0,0 +1,53 @@
+// Package cloud Streams the points read by the point cloud packages
+// (ply, pcd, xyz and las) into octrees.
+package cloud
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/bjnsn/go-octree/octree"
+)
+
+// ReadFunc Reads the next element of a file and the point it is at,
+// returning io.EOF after the last one.
+type ReadFunc[T any] func() (T, octree.Vector3f, error)
+
+// ReadInto Adds the elements returned by read to the tree at their points,
+// until read returns io.EOF, and returns any other error it returns. An error
+// adding an element is wrapped with the description returned by where.
+func ReadInto[T any](o *octree.Octree[T], read ReadFunc[T], where func() string) error {
+	for {
+		element, point, err := read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if _, err := o.AddE(element, point); err != nil {
+			return fmt.Errorf("%s: %w", where(), err)
+		}
+	}
+}
+
+// Build Reads all of the elements returned by read, until it returns
+// io.EOF, and builds a tree bounded by the box around their points with
+// octree.BuildOctree.
+func Build[T any](read ReadFunc[T], opts ...octree.Option) (*octree.Octree[T], error) {
+	var points []octree.Vector3f
+	var elements []T
+	for {
+		element, point, err := read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		points = append(points, point)
+		elements = append(elements, element)
+	}
+
+	return octree.BuildOctree(points, elements, opts...)
+}
@@ -0,0 +1,93 @@
+package octree
+
+import "iter"
+
+// All Returns an iterator over the elements in the tree and the points they
+// were added at, without collecting them into a slice. Elements added with
+// AddBox are not included; see Volumes. The tree must not be modified while
+// iterating.
+func (o *Octree[T]) All() iter.Seq2[Vector3f, T] {
+	return func(yield func(Vector3f, T) bool) {
+		o.root.each(nil, yield)
+	}
+}
+
+// InBox Returns an iterator over the elements at points within the specified
+// box and the points they were added at, as All does.
+func (o *Octree[T]) InBox(box Box) iter.Seq2[Vector3f, T] {
+	return func(yield func(Vector3f, T) bool) {
+		if o.root.box.Intersects(&box) {
+			o.root.each(&box, yield)
+		}
+	}
+}
+
+// Volumes Returns an iterator over the elements added with AddBox and their boxes.
+// The tree must not be modified while iterating.
+func (o *Octree[T]) Volumes() iter.Seq2[Box, T] {
+	return func(yield func(Box, T) bool) {
+		for n := range o.Nodes() {
+			for i := range n.volumes {
+				if !yield(n.volumes[i].box, n.volumes[i].element) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Nodes Returns an iterator over the nodes of the tree, each before its
+// children. The tree must not be modified while iterating.
+func (o *Octree[T]) Nodes() iter.Seq[*Node[T]] {
+	return func(yield func(*Node[T]) bool) {
+		o.root.eachNode(yield)
+	}
+}
+
+func (n *Node[T]) each(box *Box, yield func(Vector3f, T) bool) bool {
+	// yield the elements in this node (or a descendant) at
+	// points within box, or all of them if box is nil, returning
+	// false once the consumer has stopped.
+
+	for i := range n.entries {
+		if box != nil && !box.ContainsPoint(&n.entries[i].point) {
+			continue
+		}
+
+		for _, element := range n.entries[i].elements {
+			if !yield(n.entries[i].point, element) {
+				return false
+			}
+		}
+	}
+
+	for _, child := range n.children {
+		if box == nil || child.box.IsContainedIn(box) {
+			// fully contained
+			if !child.each(nil, yield) {
+				return false
+			}
+		} else if child.box.Intersects(box) {
+			// partially contained
+			if !child.each(box, yield) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func (n *Node[T]) eachNode(yield func(*Node[T]) bool) bool {
+	if !yield(n) {
+		return false
+	}
+
+	for _, child := range n.children {
+		if !child.eachNode(yield) {
+			return false
+		}
+	}
+
+	return true
+}
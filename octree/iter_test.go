@@ -0,0 +1,77 @@
+package octree
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestIteratesElements(t *testing.T) {
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+	o.Add(1, Vector3f{0.1, 0.1, 0.1})
+	o.Add(2, Vector3f{0.1, 0.1, 0.1})
+	o.Add(3, Vector3f{0.9, 0.9, 0.9})
+	o.AddBox(4, Box{Vector3f{0.2, 0.2, 0.2}, Vector3f{0.6, 0.6, 0.6}})
+
+	points := map[int]Vector3f{}
+	for point, element := range o.All() {
+		points[element] = point
+	}
+	equals(t, map[int]Vector3f{1: {0.1, 0.1, 0.1}, 2: {0.1, 0.1, 0.1}, 3: {0.9, 0.9, 0.9}}, points)
+
+	var inBox []int
+	for _, element := range o.InBox(Box{Vector3f{0.5, 0.5, 0.5}, Vector3f{1, 1, 1}}) {
+		inBox = append(inBox, element)
+	}
+	equals(t, []int{3}, inBox)
+
+	for box, element := range o.Volumes() {
+		equals(t, 4, element)
+		equals(t, Box{Vector3f{0.2, 0.2, 0.2}, Vector3f{0.6, 0.6, 0.6}}, box)
+	}
+
+	// stops when the consumer does
+	count := 0
+	for range o.All() {
+		count++
+		break
+	}
+	equals(t, 1, count)
+
+	var nodes []*Node[int]
+	for n := range o.Nodes() {
+		nodes = append(nodes, n)
+	}
+	equals(t, 9, len(nodes))
+	equals(t, o.root, nodes[0])
+	equals(t, o.root.children[0], nodes[1])
+
+	count = 0
+	for range o.Nodes() {
+		count++
+		if count == 2 {
+			break
+		}
+	}
+	equals(t, 2, count)
+}
+
+func TestInBoxMatchesElementsIn(t *testing.T) {
+	r := rand.New(rand.NewSource(16))
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1}, WithCapacity(3))
+	for i := 0; i < 2000; i++ {
+		o.Add(i, Vector3f{float64(r.Intn(9)) / 8, float64(r.Intn(9)) / 8, r.Float64()})
+	}
+
+	for i := 0; i < 50; i++ {
+		a := Vector3f{float64(r.Intn(9)) / 8, float64(r.Intn(9)) / 8, r.Float64()}
+		b := Vector3f{float64(r.Intn(9)) / 8, float64(r.Intn(9)) / 8, r.Float64()}
+		box := Box{a.Min(&b), a.Max(&b)}
+
+		exp := []int{}
+		for point, element := range o.InBox(box) {
+			equals(t, true, box.ContainsPoint(&point))
+			exp = append(exp, element)
+		}
+		equals(t, sorted(o.ElementsIn(box)), sorted(exp))
+	}
+}
@@ -0,0 +1,157 @@
+package octree
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// jsonTree The flat form of a tree; its bounds, and the elements
+// it holds in the order All and Volumes iterate over them.
+type jsonTree[T any] struct {
+	Bounds  *Box            `json:"bounds"`
+	Points  []jsonPoint[T]  `json:"points"`
+	Volumes []jsonVolume[T] `json:"volumes"`
+}
+
+type jsonPoint[T any] struct {
+	Point   Vector3f `json:"point"`
+	Element T        `json:"element"`
+}
+
+type jsonVolume[T any] struct {
+	Box     Box `json:"box"`
+	Element T   `json:"element"`
+}
+
+// jsonNode The nested form of a node.
+type jsonNode[T any] struct {
+	Bounds   Box             `json:"bounds"`
+	Points   []jsonEntry[T]  `json:"points,omitempty"`
+	Volumes  []jsonVolume[T] `json:"volumes,omitempty"`
+	Children []*Node[T]      `json:"children,omitempty"`
+}
+
+type jsonEntry[T any] struct {
+	Point    Vector3f `json:"point"`
+	Elements []T      `json:"elements"`
+}
+
+// MarshalJSON Encodes the tree as a flat list of its elements; an object
+// with the tree's "bounds", its "points", each an object with a "point" and
+// an "element", and its "volumes" (see AddBox), each with a "box" and an
+// "element". For the tree's structure, encode its Root instead. Returns an
+// error for the zero value, which has no bounds.
+func (o *Octree[T]) MarshalJSON() ([]byte, error) {
+	if o.root == nil {
+		return nil, fmt.Errorf("octree: tree has no bounds")
+	}
+
+	flat := jsonTree[T]{Bounds: &o.root.box, Points: []jsonPoint[T]{}, Volumes: []jsonVolume[T]{}}
+	for point, element := range o.All() {
+		flat.Points = append(flat.Points, jsonPoint[T]{point, element})
+	}
+	for box, element := range o.Volumes() {
+		flat.Volumes = append(flat.Volumes, jsonVolume[T]{box, element})
+	}
+	return json.Marshal(&flat)
+}
+
+// UnmarshalJSON Replaces the contents of the tree with those of a tree
+// encoded by MarshalJSON, adding its elements in order to a tree with its
+// bounds, giving a tree equivalent to the one encoded. The tree's settings
+// are kept, unless it is the zero value, where those of CreateOctree are
+// used. Returns an error, leaving the tree unchanged, if the JSON is invalid
+// or any of the elements can't be added.
+func (o *Octree[T]) UnmarshalJSON(data []byte) error {
+	var flat jsonTree[T]
+	if err := json.Unmarshal(data, &flat); err != nil {
+		return err
+	}
+	if flat.Bounds == nil {
+		return fmt.Errorf("octree: tree has no bounds")
+	}
+
+	var t Octree[T]
+	if o.root == nil {
+		t = *CreateOctree[T](flat.Bounds.min, flat.Bounds.max)
+	} else {
+		t = *o
+		t.gen = nextGeneration()
+		t.root = &Node[T]{box: *flat.Bounds, gen: t.gen}
+	}
+
+	for i := range flat.Points {
+		if _, err := t.AddE(flat.Points[i].Element, flat.Points[i].Point); err != nil {
+			return fmt.Errorf("octree: adding point %d: %w", i, err)
+		}
+	}
+
+	for i := range flat.Volumes {
+		if _, err := t.AddBoxE(flat.Volumes[i].Element, flat.Volumes[i].Box); err != nil {
+			return fmt.Errorf("octree: adding volume %d: %w", i, err)
+		}
+	}
+
+	*o = t
+	return nil
+}
+
+// MarshalJSON Encodes the node and its descendants; an object with the node's
+// "bounds", the "points" it holds, each an object with a "point" and its
+// "elements", the "volumes" it holds, each with a "box" and an "element", and
+// its "children"; all eight of them, empty or not, left out for a leaf.
+func (n *Node[T]) MarshalJSON() ([]byte, error) {
+	nested := jsonNode[T]{Bounds: n.box, Children: n.children}
+	for i := range n.entries {
+		nested.Points = append(nested.Points, jsonEntry[T]{n.entries[i].point, n.entries[i].elements})
+	}
+	for i := range n.volumes {
+		nested.Volumes = append(nested.Volumes, jsonVolume[T]{n.volumes[i].box, n.volumes[i].element})
+	}
+	return json.Marshal(&nested)
+}
+
+// MarshalJSON Encodes the box as an object with its "min" and "max" corners.
+func (b Box) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Min Vector3f `json:"min"`
+		Max Vector3f `json:"max"`
+	}{b.min, b.max})
+}
+
+// UnmarshalJSON Decodes a box encoded by MarshalJSON, swapping
+// the coordinates of its corners where they are reversed.
+func (b *Box) UnmarshalJSON(data []byte) error {
+	var corners struct {
+		Min *Vector3f `json:"min"`
+		Max *Vector3f `json:"max"`
+	}
+	if err := json.Unmarshal(data, &corners); err != nil {
+		return err
+	}
+	if corners.Min == nil || corners.Max == nil {
+		return fmt.Errorf("octree: box %s needs a min and a max", data)
+	}
+
+	*b = NewBox(*corners.Min, *corners.Max)
+	return nil
+}
+
+// MarshalJSON Encodes the vector as an array of its three coordinates.
+func (v Vector3f) MarshalJSON() ([]byte, error) {
+	return json.Marshal([3]float64(v))
+}
+
+// UnmarshalJSON Decodes a vector from an array of exactly three numbers.
+func (v *Vector3f) UnmarshalJSON(data []byte) error {
+	var coords []float64
+	if err := json.Unmarshal(data, &coords); err != nil {
+		return err
+	}
+	if len(coords) != 3 {
+		return fmt.Errorf("octree: vector %s doesn't have three coordinates", data)
+	}
+
+	*v = Vector3f(coords)
+	return nil
+}
@@ -0,0 +1,106 @@
+package octree
+
+import (
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestJSONRoundTrips(t *testing.T) {
+	r := rand.New(rand.NewSource(21))
+
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 2, 1}, WithCapacity(2))
+	for i := 0; i < 500; i++ {
+		o.Add(i, Vector3f{float64(r.Intn(17)) / 16, float64(r.Intn(33)) / 16, float64(r.Intn(17)) / 16})
+	}
+
+	data, err := json.Marshal(o)
+	equals(t, nil, err)
+
+	// the settings of the tree unmarshalled into are kept
+	read := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1}, WithCapacity(2))
+	read.Add(-1, Vector3f{0.5, 0.5, 0.5})
+	equals(t, nil, json.Unmarshal(data, read))
+	sameNodes(t, o.root, read.root)
+	equals(t, nil, read.Validate())
+
+	// or those of CreateOctree, for the zero value
+	var zero Octree[int]
+	equals(t, nil, json.Unmarshal(data, &zero))
+	equals(t, 1, zero.capacity)
+	equals(t, o.root.box, zero.root.box)
+	equals(t, nil, zero.Validate())
+	for point, element := range o.All() {
+		equals(t, true, slices.Contains(zero.ElementsAt(point), element))
+	}
+
+	// which can't be marshalled, having no bounds
+	_, err = json.Marshal(&Octree[int]{})
+	equals(t, false, err == nil)
+
+	// volumes are added after the points
+	o.AddBox(1000, NewBox(Vector3f{0.1, 0.1, 0.1}, Vector3f{0.3, 0.3, 0.3}))
+	o.AddBox(1001, NewBox(Vector3f{0, 0, 0}, Vector3f{1, 2, 1}))
+	data, err = json.Marshal(o)
+	equals(t, nil, err)
+	equals(t, nil, json.Unmarshal(data, read))
+	equals(t, []int{1001, 1000}, read.ElementsContaining(Vector3f{0.2, 0.2, 0.2}))
+	equals(t, o.root.count, read.root.count)
+}
+
+func TestJSONFormats(t *testing.T) {
+	o := CreateOctree[string](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+	o.Add("a", Vector3f{0.25, 0.25, 0.25})
+	o.Add("b", Vector3f{0.25, 0.25, 0.25})
+	o.Add("c", Vector3f{1, 1, 1})
+	o.AddBox("v", NewBox(Vector3f{0.4, 0.4, 0.4}, Vector3f{0.6, 0.6, 0.6}))
+
+	data, err := json.Marshal(o)
+	equals(t, nil, err)
+	equals(t, `{"bounds":{"min":[0,0,0],"max":[1,1,1]},`+
+		`"points":[{"point":[0.25,0.25,0.25],"element":"a"},{"point":[0.25,0.25,0.25],"element":"b"},{"point":[1,1,1],"element":"c"}],`+
+		`"volumes":[{"box":{"min":[0.4,0.4,0.4],"max":[0.6,0.6,0.6]},"element":"v"}]}`, string(data))
+
+	data, err = json.Marshal(o.Root().children[7])
+	equals(t, nil, err)
+	equals(t, `{"bounds":{"min":[0.5,0.5,0.5],"max":[1,1,1]},`+
+		`"points":[{"point":[1,1,1],"elements":["c"]}]}`, string(data))
+
+	data, err = json.Marshal(o.Root())
+	equals(t, nil, err)
+	var nested struct {
+		Children []json.RawMessage `json:"children"`
+	}
+	equals(t, nil, json.Unmarshal(data, &nested))
+	equals(t, 8, len(nested.Children))
+	equals(t, `{"bounds":{"min":[0.5,0,0],"max":[1,0.5,0.5]}}`, string(nested.Children[1]))
+
+	// boxes are decoded with their corners in order
+	var box Box
+	equals(t, nil, json.Unmarshal([]byte(`{"min":[1,0,1],"max":[0,1,0]}`), &box))
+	equals(t, NewBox(Vector3f{0, 0, 0}, Vector3f{1, 1, 1}), box)
+}
+
+func TestJSONReportsErrors(t *testing.T) {
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+	o.Add(1, Vector3f{0.5, 0.5, 0.5})
+
+	for _, data := range []string{
+		`{"points":[]}`,
+		`{"bounds":{"min":[0,0,0]}}`,
+		`{"bounds":{"min":[0,0],"max":[1,1,1]}}`,
+		`{"bounds":{"min":[0,0,0],"max":[1,1,1]},"points":[{"point":[0,0,0],"element":"a"}]}`,
+		`[]`,
+	} {
+		equals(t, false, json.Unmarshal([]byte(data), o) == nil)
+	}
+
+	err := json.Unmarshal([]byte(`{"bounds":{"min":[0,0,0],"max":[1,1,1]},"points":[{"point":[2,0,0],"element":2}]}`), o)
+	equals(t, true, errors.Is(err, ErrOutOfBounds))
+
+	// leaving the tree unchanged
+	equals(t, []int{1}, o.ElementsAt(Vector3f{0.5, 0.5, 0.5}))
+	equals(t, 1, o.root.count)
+}
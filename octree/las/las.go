@@ -0,0 +1,210 @@
+// Package las Reads aerial LiDAR point clouds from uncompressed LAS files,
+// versions 1.0 to 1.4, into octrees.
+package las
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/bjnsn/go-octree/octree"
+)
+
+// ErrFormat The data read isn't an uncompressed LAS file with a supported
+// point data record format, or holds points that can't be read.
+var ErrFormat = errors.New("las: invalid format")
+
+// Header The public header block of a LAS file.
+type Header struct {
+	VersionMajor, VersionMinor uint8
+	SystemIdentifier           string
+	GeneratingSoftware         string
+	// PointFormat is the point data record format; 0 to 3, or 6 to 8.
+	PointFormat uint8
+	// Points is the number of point records in the file.
+	Points uint64
+	// Scale and Offset give the position of a point from its
+	// integer coordinates, as coordinate * Scale + Offset.
+	Scale, Offset octree.Vector3f
+	// Min and Max are the corners of the box around the points.
+	Min, Max octree.Vector3f
+
+	recordLength int
+	dataOffset   uint32
+	size         int
+}
+
+// Bounds Returns the box around the points, widened by a step of the scale
+// in each dimension, as the corners given by the header are rounded.
+func (h *Header) Bounds() octree.Box {
+	min := h.Min.Minus(&h.Scale)
+	max := h.Max.Plus(&h.Scale)
+	return octree.NewBox(min, max)
+}
+
+// Point A point of a LAS file. Each is held as the element at its position in
+// a tree, so that queries can be filtered by classification or return.
+type Point struct {
+	Position  octree.Vector3f
+	Intensity uint16
+	// ReturnNumber is the 1-based index of the return of the pulse,
+	// of NumberOfReturns, that the point was made by.
+	ReturnNumber    uint8
+	NumberOfReturns uint8
+	// Classification is the ASPRS class of the point, e.g. 2 for ground.
+	Classification uint8
+	Synthetic      bool
+	KeyPoint       bool
+	Withheld       bool
+	// Overlap is only given by point formats 6 to 8.
+	Overlap bool
+	// ScanAngle is in degrees, with 0 at nadir.
+	ScanAngle     float64
+	UserData      uint8
+	PointSourceID uint16
+	// GPSTime is only given by point formats 1, 3 and 6 to 8.
+	GPSTime float64
+	// Color is the red, green and blue of the point, given by formats 2, 3, 7 and 8.
+	Color [3]uint16
+	// NIR is the near infrared of the point, given by format 8.
+	NIR uint16
+}
+
+// the length of the records of each point format, and the offset
+// of their GPS time and color; -1 for formats without them
+var recordLengths = map[uint8]int{0: 20, 1: 28, 2: 26, 3: 34, 6: 30, 7: 36, 8: 38}
+var gpsTimes = map[uint8]int{0: -1, 1: 20, 2: -1, 3: 20, 6: 22, 7: 22, 8: 22}
+var colors = map[uint8]int{0: -1, 1: -1, 2: 20, 3: 28, 6: -1, 7: 30, 8: 30}
+
+func formatError(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %v", ErrFormat, fmt.Sprintf(format, args...))
+}
+
+func readHeader(r io.Reader) (*Header, error) {
+	// read the public header block, which is at least the 227 bytes
+	// of version 1.2; later versions add to the end of it.
+
+	b := make([]byte, 227)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, formatError("file ends in its header")
+	}
+	if string(b[:4]) != "LASF" {
+		return nil, formatError("file starts with %q rather than LASF", b[:4])
+	}
+
+	le := binary.LittleEndian
+	h := &Header{
+		VersionMajor:       b[24],
+		VersionMinor:       b[25],
+		SystemIdentifier:   text(b[26:58]),
+		GeneratingSoftware: text(b[58:90]),
+		size:               int(le.Uint16(b[94:])),
+		dataOffset:         le.Uint32(b[96:]),
+		PointFormat:        b[104],
+		recordLength:       int(le.Uint16(b[105:])),
+		Points:             uint64(le.Uint32(b[107:])),
+	}
+
+	for i := 0; i < 3; i++ {
+		h.Scale[i] = math.Float64frombits(le.Uint64(b[131+8*i:]))
+		h.Offset[i] = math.Float64frombits(le.Uint64(b[155+8*i:]))
+		h.Max[i] = math.Float64frombits(le.Uint64(b[179+16*i:]))
+		h.Min[i] = math.Float64frombits(le.Uint64(b[187+16*i:]))
+	}
+
+	if h.VersionMajor != 1 || h.VersionMinor > 4 {
+		return nil, formatError("unsupported version %d.%d", h.VersionMajor, h.VersionMinor)
+	}
+	if h.PointFormat&0xc0 != 0 {
+		return nil, formatError("compressed (LAZ) points are not supported")
+	}
+	if recordLengths[h.PointFormat] == 0 {
+		return nil, formatError("unsupported point format %d", h.PointFormat)
+	}
+	if h.recordLength < recordLengths[h.PointFormat] {
+		return nil, formatError("point records of %d bytes are too short for format %d", h.recordLength, h.PointFormat)
+	}
+	if h.size < len(b) || h.dataOffset < uint32(h.size) {
+		return nil, formatError("invalid header size %d or offset to point data %d", h.size, h.dataOffset)
+	}
+
+	for i := 0; i < 3; i++ {
+		if !(h.Scale[i] > 0) || math.IsInf(h.Scale[i], 0) || math.IsNaN(h.Offset[i]) || math.IsInf(h.Offset[i], 0) {
+			return nil, formatError("invalid scale %v or offset %v", h.Scale[i], h.Offset[i])
+		}
+		if !(h.Min[i] <= h.Max[i]) || math.IsInf(h.Min[i], 0) || math.IsInf(h.Max[i], 0) {
+			return nil, formatError("invalid bounds %v to %v", h.Min[i], h.Max[i])
+		}
+	}
+
+	// the rest of the header, of which version 1.4 has the 64 bit number of points
+	rest := make([]byte, h.size-len(b))
+	if _, err := io.ReadFull(r, rest); err != nil {
+		return nil, formatError("file ends in its header")
+	}
+	if h.VersionMinor >= 4 && len(rest) >= 247+8-227 {
+		if points := le.Uint64(rest[247-227:]); points != 0 {
+			h.Points = points
+		}
+	}
+
+	return h, nil
+}
+
+func text(b []byte) string {
+	// a string padded with zeros
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
+
+func (h *Header) decode(b []byte) Point {
+	// the point of the record, which is at least as
+	// long as those of the header's point format.
+	le := binary.LittleEndian
+
+	var p Point
+	for i := 0; i < 3; i++ {
+		p.Position[i] = float64(int32(le.Uint32(b[4*i:])))*h.Scale[i] + h.Offset[i]
+	}
+	p.Intensity = le.Uint16(b[12:])
+
+	if h.PointFormat < 6 {
+		p.ReturnNumber = b[14] & 0x07
+		p.NumberOfReturns = b[14] >> 3 & 0x07
+		p.Classification = b[15] & 0x1f
+		p.Synthetic = b[15]&0x20 != 0
+		p.KeyPoint = b[15]&0x40 != 0
+		p.Withheld = b[15]&0x80 != 0
+		p.ScanAngle = float64(int8(b[16]))
+		p.UserData = b[17]
+		p.PointSourceID = le.Uint16(b[18:])
+	} else {
+		p.ReturnNumber = b[14] & 0x0f
+		p.NumberOfReturns = b[14] >> 4
+		p.Synthetic = b[15]&0x01 != 0
+		p.KeyPoint = b[15]&0x02 != 0
+		p.Withheld = b[15]&0x04 != 0
+		p.Overlap = b[15]&0x08 != 0
+		p.Classification = b[16]
+		p.UserData = b[17]
+		p.ScanAngle = float64(int16(le.Uint16(b[18:]))) * 0.006
+		p.PointSourceID = le.Uint16(b[20:])
+	}
+
+	if offset := gpsTimes[h.PointFormat]; offset >= 0 {
+		p.GPSTime = math.Float64frombits(le.Uint64(b[offset:]))
+	}
+	if offset := colors[h.PointFormat]; offset >= 0 {
+		p.Color = [3]uint16{le.Uint16(b[offset:]), le.Uint16(b[offset+2:]), le.Uint16(b[offset+4:])}
+		if h.PointFormat == 8 {
+			p.NIR = le.Uint16(b[offset+6:])
+		}
+	}
+
+	return p
+}
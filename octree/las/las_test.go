@@ -0,0 +1,220 @@
+package las
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/bjnsn/go-octree/octree"
+	"github.com/bjnsn/go-octree/octree/internal/testutil"
+)
+
+var equals = testutil.Equals
+
+var scale = octree.Vector3f{0.01, 0.01, 0.001}
+var offset = octree.Vector3f{500000, 4000000, 0}
+
+// samples Points that each point format can hold all of, with positions
+// on the grid given by scale and offset.
+func samples(format uint8) []Point {
+	points := []Point{
+		{Position: octree.Vector3f{500000.25, 4000010.5, 12.345}, Intensity: 100, ReturnNumber: 1, NumberOfReturns: 2,
+			Classification: 2, KeyPoint: true, ScanAngle: -12, UserData: 7, PointSourceID: 3},
+		{Position: octree.Vector3f{500001.5, 4000000.75, 3.5}, Intensity: 65535, ReturnNumber: 2, NumberOfReturns: 2,
+			Classification: 5, Withheld: true, ScanAngle: 30, PointSourceID: 3},
+		{Position: octree.Vector3f{500000, 4000000, 0}, ReturnNumber: 1, NumberOfReturns: 1, Classification: 2, Synthetic: true},
+	}
+
+	for i := range points {
+		p := &points[i]
+		if format >= 6 {
+			p.Overlap = i == 2
+			p.Classification += 40
+			p.ReturnNumber += 8
+			p.NumberOfReturns += 8
+			p.ScanAngle = float64(int16(p.ScanAngle/0.006)) * 0.006
+		}
+		if gpsTimes[format] >= 0 {
+			p.GPSTime = 1e9 + float64(i)/4
+		}
+		if colors[format] >= 0 {
+			p.Color = [3]uint16{uint16(i), 256, 65535}
+		}
+		if format == 8 {
+			p.NIR = uint16(1000 * i)
+		}
+	}
+
+	return points
+}
+
+// encode Writes the points as a LAS file of the version and point format,
+// with a variable length record and extra bytes on the end of each record.
+func encode(minor, format uint8, points []Point) []byte {
+	le := binary.LittleEndian
+	headerSize := map[uint8]int{0: 227, 1: 227, 2: 227, 3: 235, 4: 375}[minor]
+	vlr := make([]byte, 54+10)
+	recordLength := recordLengths[format] + 2
+
+	b := make([]byte, headerSize)
+	copy(b, "LASF")
+	b[24], b[25] = 1, minor
+	copy(b[26:], "test")
+	copy(b[58:], "go-octree")
+	le.PutUint16(b[94:], uint16(headerSize))
+	le.PutUint32(b[96:], uint32(headerSize+len(vlr)))
+	le.PutUint32(b[100:], 1)
+	b[104] = format
+	le.PutUint16(b[105:], uint16(recordLength))
+	if format < 6 {
+		le.PutUint32(b[107:], uint32(len(points)))
+	}
+
+	min, max := points[0].Position, points[0].Position
+	for i := range points {
+		min = min.Min(&points[i].Position)
+		max = max.Max(&points[i].Position)
+	}
+	for i := 0; i < 3; i++ {
+		le.PutUint64(b[131+8*i:], math.Float64bits(scale[i]))
+		le.PutUint64(b[155+8*i:], math.Float64bits(offset[i]))
+		le.PutUint64(b[179+16*i:], math.Float64bits(max[i]))
+		le.PutUint64(b[187+16*i:], math.Float64bits(min[i]))
+	}
+	if minor == 4 {
+		le.PutUint64(b[247:], uint64(len(points)))
+	}
+	b = append(b, vlr...)
+
+	for _, p := range points {
+		r := make([]byte, recordLength)
+		for i := 0; i < 3; i++ {
+			le.PutUint32(r[4*i:], uint32(int32(math.Round((p.Position[i]-offset[i])/scale[i]))))
+		}
+		le.PutUint16(r[12:], p.Intensity)
+
+		if format < 6 {
+			r[14] = p.ReturnNumber | p.NumberOfReturns<<3
+			r[15] = p.Classification | flag(p.Synthetic)<<5 | flag(p.KeyPoint)<<6 | flag(p.Withheld)<<7
+			r[16] = byte(int8(p.ScanAngle))
+			r[17] = p.UserData
+			le.PutUint16(r[18:], p.PointSourceID)
+		} else {
+			r[14] = p.ReturnNumber | p.NumberOfReturns<<4
+			r[15] = flag(p.Synthetic) | flag(p.KeyPoint)<<1 | flag(p.Withheld)<<2 | flag(p.Overlap)<<3
+			r[16] = p.Classification
+			r[17] = p.UserData
+			le.PutUint16(r[18:], uint16(int16(math.Round(p.ScanAngle/0.006))))
+			le.PutUint16(r[20:], p.PointSourceID)
+		}
+
+		if offset := gpsTimes[format]; offset >= 0 {
+			le.PutUint64(r[offset:], math.Float64bits(p.GPSTime))
+		}
+		if offset := colors[format]; offset >= 0 {
+			for i := 0; i < 3; i++ {
+				le.PutUint16(r[offset+2*i:], p.Color[i])
+			}
+			le.PutUint16(r[offset+6:], p.NIR)
+		}
+		b = append(b, r...)
+	}
+
+	return b
+}
+
+func flag(b bool) uint8 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func TestReadsPointFormats(t *testing.T) {
+	for _, test := range []struct{ minor, format uint8 }{
+		{2, 0}, {2, 1}, {2, 2}, {2, 3}, {3, 1}, {4, 1}, {4, 6}, {4, 7}, {4, 8},
+	} {
+		points := samples(test.format)
+		reader, err := NewReader(bytes.NewReader(encode(test.minor, test.format, points)))
+		equals(t, nil, err)
+
+		header := reader.Header()
+		equals(t, test.minor, header.VersionMinor)
+		equals(t, test.format, header.PointFormat)
+		equals(t, uint64(len(points)), header.Points)
+		equals(t, "go-octree", header.GeneratingSoftware)
+		equals(t, scale, header.Scale)
+
+		for i := range points {
+			point, err := reader.Read()
+			equals(t, nil, err)
+
+			// positions are only as exact as the scale
+			for j := 0; j < 3; j++ {
+				equals(t, true, math.Abs(point.Position[j]-points[i].Position[j]) < scale[j]/10)
+			}
+			point.Position = points[i].Position
+			equals(t, points[i], point)
+		}
+		_, err = reader.Read()
+		equals(t, io.EOF, err)
+	}
+}
+
+func TestLoadsIntoTree(t *testing.T) {
+	o, header, err := Load(bytes.NewReader(encode(4, 6, samples(6))), octree.WithCapacity(2))
+	equals(t, nil, err)
+	equals(t, header.Bounds(), o.Root().Bounds())
+	equals(t, nil, o.Validate())
+
+	// filtered by classification
+	ground := 0
+	for _, point := range o.InBox(header.Bounds()) {
+		if point.Classification == 42 {
+			ground++
+		}
+	}
+	equals(t, 2, ground)
+
+	// or into a tree of your own
+	tree := octree.CreateOctree[Point](octree.Vector3f{500000, 4000000, 0}, octree.Vector3f{500002, 4000011, 13})
+	_, err = ReadInto(bytes.NewReader(encode(2, 0, samples(0))), tree)
+	equals(t, nil, err)
+	equals(t, 3, tree.Stats().Elements)
+}
+
+func TestReportsErrors(t *testing.T) {
+	valid := encode(2, 1, samples(1))
+
+	corrupt := func(at int, b ...byte) []byte {
+		c := append([]byte{}, valid...)
+		copy(c[at:], b)
+		return c
+	}
+
+	for _, data := range [][]byte{
+		nil,
+		valid[:100],
+		corrupt(0, 'L', 'A', 'Z', 'F'),
+		corrupt(24, 2),
+		corrupt(104, 4),
+		corrupt(104, 0x81),
+		corrupt(105, 10, 0),
+		corrupt(94, 100, 0),
+		corrupt(131, 0, 0, 0, 0, 0, 0, 0, 0),
+		valid[:230],
+	} {
+		_, err := NewReader(bytes.NewReader(data))
+		equals(t, true, errors.Is(err, ErrFormat))
+	}
+
+	_, _, err := Load(bytes.NewReader(valid[:len(valid)-1]))
+	equals(t, true, errors.Is(err, ErrFormat))
+
+	o := octree.CreateOctree[Point](octree.Vector3f{0, 0, 0}, octree.Vector3f{1, 1, 1})
+	_, err = ReadInto(bytes.NewReader(valid), o)
+	equals(t, true, errors.Is(err, octree.ErrOutOfBounds))
+}
@@ -0,0 +1,101 @@
+package las
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/bjnsn/go-octree/octree"
+	"github.com/bjnsn/go-octree/octree/internal/cloud"
+)
+
+// Reader Reads the point records of a LAS file in order, ignoring any
+// extra bytes at the end of each record.
+type Reader struct {
+	r      *bufio.Reader
+	header *Header
+	record []byte
+	read   uint64
+}
+
+// NewReader Reads the header of the LAS file read from r, and skips its
+// variable length records, so that Read returns its first point. Returns an
+// error wrapping ErrFormat if r doesn't hold an uncompressed LAS file with
+// point data record format 0 to 3, or 6 to 8.
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	header, err := readHeader(br)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := br.Discard(int(header.dataOffset) - header.size); err != nil {
+		return nil, formatError("file ends before its point data")
+	}
+
+	return &Reader{r: br, header: header, record: make([]byte, header.recordLength)}, nil
+}
+
+// Header Returns the header of the file.
+func (r *Reader) Header() *Header {
+	return r.header
+}
+
+// Read Reads the next point, returning io.EOF after the last one, or an
+// error wrapping ErrFormat if the file ends before it.
+func (r *Reader) Read() (Point, error) {
+	if r.read == r.header.Points {
+		return Point{}, io.EOF
+	}
+
+	if _, err := io.ReadFull(r.r, r.record); err != nil {
+		return Point{}, formatError("file ends early (point %d)", r.read)
+	}
+
+	r.read++
+	return r.header.decode(r.record), nil
+}
+
+// ReadInto Streams the points of the LAS file read from r into the tree,
+// returning the header of the file. Returns an error wrapping ErrFormat if
+// the file can't be read, or an *octree.PointError if a point can't be added,
+// such as one outside a tree bounded by less than the header's Bounds. A
+// survey split between several files can be read into the one tree, created
+// with the bounds of all of their headers or with octree.WithGrowth.
+func ReadInto(r io.Reader, o *octree.Octree[Point]) (*Header, error) {
+	reader, err := NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cloud.ReadInto(o, reader.next, reader.where); err != nil {
+		return nil, err
+	}
+	return reader.Header(), nil
+}
+
+// Load Reads the points of the LAS file read from r into a tree created with
+// the bounds given by its header (see Header.Bounds) and the options, and
+// returns it along with the header.
+func Load(r io.Reader, opts ...octree.Option) (*octree.Octree[Point], *Header, error) {
+	reader, err := NewReader(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bounds := reader.Header().Bounds()
+	o := octree.CreateOctree[Point](bounds.Min(), bounds.Max(), opts...)
+	if err := cloud.ReadInto(o, reader.next, reader.where); err != nil {
+		return nil, nil, err
+	}
+	return o, reader.Header(), nil
+}
+
+func (r *Reader) next() (Point, octree.Vector3f, error) {
+	point, err := r.Read()
+	return point, point.Position, err
+}
+
+func (r *Reader) where() string {
+	return fmt.Sprintf("las: adding point %d", r.read-1)
+}
@@ -0,0 +1,118 @@
+package octree
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestLooseTreePlacesStraddlingVolumes(t *testing.T) {
+	straddling := Box{Vector3f{0.45, 0.2, 0.2}, Vector3f{0.55, 0.3, 0.3}}
+
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+	o.Add(1, Vector3f{0.9, 0.9, 0.9})
+	equals(t, o.root, o.AddBox(2, straddling))
+
+	o = CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1}, WithLooseness(2))
+	o.Add(1, Vector3f{0.9, 0.9, 0.9})
+	node := o.AddBox(2, straddling)
+	equals(t, o.root.children[0], node)
+	equals(t, Box{Vector3f{-0.25, -0.25, -0.25}, Vector3f{0.75, 0.75, 0.75}}, o.looseBox(node))
+
+	// found through the parts outside the node's box
+	equals(t, []int{2}, o.ElementsContaining(Vector3f{0.54, 0.25, 0.25}))
+	equals(t, []int{2}, o.ElementsIn(Box{Vector3f{0.52, 0.2, 0.2}, Vector3f{0.6, 0.3, 0.3}}))
+	equals(t, []int{2}, o.ElementsWithin(Vector3f{0.6, 0.25, 0.25}, 0.06))
+
+	// too large for the octant containing its center
+	equals(t, o.root, o.AddBox(3, Box{Vector3f{0.2, 0.2, 0.2}, Vector3f{0.8, 0.4, 0.4}}))
+}
+
+func TestMovesVolumes(t *testing.T) {
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1}, WithLooseness(2))
+	o.Add(1, Vector3f{0.9, 0.9, 0.9})
+	node := o.AddBox(2, Box{Vector3f{0.2, 0.2, 0.2}, Vector3f{0.3, 0.3, 0.3}})
+	equals(t, o.root.children[0], node)
+
+	// moving across the center plane doesn't change node
+	moved := o.MoveBox(2, node, Box{Vector3f{0.45, 0.2, 0.2}, Vector3f{0.55, 0.3, 0.3}})
+	equals(t, node, moved)
+	equals(t, []int{2}, o.ElementsContaining(Vector3f{0.5, 0.25, 0.25}))
+	equals(t, 0, len(o.ElementsContaining(Vector3f{0.25, 0.25, 0.25})))
+
+	// but moving its center into another octant does
+	moved = o.MoveBox(2, moved, Box{Vector3f{0.6, 0.6, 0.6}, Vector3f{0.7, 0.7, 0.7}})
+	equals(t, o.root.children[7], moved.parent)
+	equals(t, []int{2}, o.ElementsContaining(Vector3f{0.65, 0.65, 0.65}))
+	checkCounts(t, o.root)
+
+	// points aren't moved as volumes, nor volumes out of bounds
+	equals(t, true, o.MoveBox(1, o.root, Box{Vector3f{0.1, 0.1, 0.1}, Vector3f{0.2, 0.2, 0.2}}) == nil)
+	equals(t, true, o.Move(2, o.root, Vector3f{0.1, 0.1, 0.1}) == nil)
+	equals(t, true, o.MoveBox(2, moved, Box{Vector3f{0.6, 0.6, 0.6}, Vector3f{1.7, 0.7, 0.7}}) == nil)
+	equals(t, []int{2}, o.ElementsContaining(Vector3f{0.65, 0.65, 0.65}))
+
+	// growing trees only grow for elements they hold, and both corners
+	o = CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1}, WithGrowth())
+	node = o.AddBox(1, Box{Vector3f{0.2, 0.2, 0.2}, Vector3f{0.3, 0.3, 0.3}})
+	bounds := o.Root().Bounds()
+	equals(t, true, o.MoveBox(2, node, Box{Vector3f{0.2, 0.2, 0.2}, Vector3f{1000, 1, 1}}) == nil)
+	equals(t, true, o.MoveBox(1, node, Box{Vector3f{-1000, 0, 0}, Vector3f{math.MaxFloat64, 1, 1}}) == nil)
+	equals(t, bounds, o.Root().Bounds())
+
+	moved = o.MoveBox(1, node, Box{Vector3f{-1000, 0, 0}, Vector3f{1000, 1, 1}})
+	equals(t, false, moved == nil)
+	equals(t, []int{1}, o.ElementsContaining(Vector3f{999, 0.5, 0.5}))
+}
+
+func TestLooseTreeMatchesBruteForce(t *testing.T) {
+	for _, looseness := range []float64{1, 1.5, 2} {
+		r := rand.New(rand.NewSource(11))
+		o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1}, WithLooseness(looseness), WithCapacity(2))
+
+		randomBox := func() Box {
+			size := r.Float64() * 0.1
+			min := Vector3f{r.Float64() * (1 - size), r.Float64() * (1 - size), r.Float64() * (1 - size)}
+			return Box{min, Vector3f{min[0] + size, min[1] + size, min[2] + size}}
+		}
+
+		boxes := make([]Box, 300)
+		nodes := make([]*Node[int], len(boxes))
+		for i := range boxes {
+			boxes[i] = randomBox()
+			nodes[i] = o.AddBox(i, boxes[i])
+		}
+
+		for step := 0; step < 1000; step++ {
+			i := r.Intn(len(boxes))
+			boxes[i] = randomBox()
+			nodes[i] = o.MoveBox(i, nodes[i], boxes[i])
+			equals(t, false, nodes[i] == nil)
+		}
+		checkCounts(t, o.root)
+
+		for q := 0; q < 20; q++ {
+			point := Vector3f{r.Float64(), r.Float64(), r.Float64()}
+			query := Box{point, Vector3f{point[0] + 0.1, point[1] + 0.1, point[2] + 0.1}}
+
+			containing := []int{}
+			overlapping := []int{}
+			within := []int{}
+			for i := range boxes {
+				if boxes[i].ContainsPoint(&point) {
+					containing = append(containing, i)
+				}
+				if boxes[i].Intersects(&query) {
+					overlapping = append(overlapping, i)
+				}
+				if boxes[i].DistanceToPoint(&point) <= 0.1 {
+					within = append(within, i)
+				}
+			}
+
+			equals(t, containing, sorted(o.ElementsContaining(point)))
+			equals(t, overlapping, sorted(o.ElementsIn(query)))
+			equals(t, within, sorted(o.ElementsWithin(point, 0.1)))
+		}
+	}
+}
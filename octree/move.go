@@ -0,0 +1,76 @@
+package octree
+
+// Move Moves the element to the specified point, returning the node it is
+// now held by. node should usually be the node returned when this element was
+// placed in the tree; rather than starting from the root, the tree is only
+// climbed from there as far as the nearest node containing the new point.
+// Returns nil, leaving the element where it was, when it isn't found or
+// the new point is outside the tree (and it was not created using WithGrowth).
+func (o *Octree[T]) Move(element T, node *Node[T], point Vector3f) *Node[T] {
+	if !o.root.box.ContainsPoint(&point) {
+		// only grow once the element is known to be held,
+		// so that the tree is left unchanged when it isn't
+		if !o.growth || !o.holds(element, node, true, false) || o.grow(&point) != nil {
+			return nil
+		}
+	}
+
+	node, region := o.resolve(node)
+	if node == nil {
+		return nil
+	}
+
+	leaf := o.remove(node, region, element, true, false)
+	if leaf == nil {
+		return nil
+	}
+
+	// climb to the nearest node that a search from
+	// the root for the new point would pass through
+	n := leaf
+	for !o.owns(n, &point) {
+		n = n.parent
+	}
+
+	// only collapse what is left behind once the element has
+	// been added again, so that moves within a leaf don't
+	// collapse and then subdivide it.
+	moved := n.tryAdd(o, n.Depth(), []T{element}, &point)
+	o.collapse(leaf)
+
+	return moved.live()
+}
+
+func (o *Octree[T]) holds(element T, node *Node[T], points, volumes bool) bool {
+	// whether a search starting from node finds the element
+	// as a point and/or as a volume, as removing it would
+	n, region := o.resolve(node)
+	return n != nil && n.find(o, element, points, volumes, region) != nil
+}
+
+func (n *Node[T]) live() *Node[T] {
+	// the node, or the ancestor it has been collapsed into
+	for n.detached {
+		n = n.parent
+	}
+	return n
+}
+
+func (o *Octree[T]) owns(n *Node[T], point *Vector3f) bool {
+	// whether a search from the root for point would pass through n.
+	// Searches take the first child containing the point, so points on
+	// the face shared with a preceding sibling belong to that sibling;
+	// n's minimum faces only belong to n where they lie on the root's.
+
+	if !n.box.ContainsPoint(point) {
+		return false
+	}
+
+	for i := 0; i < 3; i++ {
+		if point[i] == n.box.min[i] && n.box.min[i] != o.root.box.min[i] {
+			return false
+		}
+	}
+
+	return true
+}
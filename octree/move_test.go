@@ -0,0 +1,87 @@
+package octree
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestMovesElements(t *testing.T) {
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+
+	o.Add(2, Vector3f{0.9, 0.9, 0.9})
+	node3 := o.Add(3, Vector3f{0.1, 0.1, 0.2})
+	node1 := o.Add(1, Vector3f{0.1, 0.1, 0.1})
+
+	// within the same octant
+	node3 = o.Move(3, node3, Vector3f{0.2, 0.1, 0.2})
+	equals(t, 0, len(o.ElementsAt(Vector3f{0.1, 0.1, 0.2})))
+	equals(t, []int{3}, o.ElementsAt(Vector3f{0.2, 0.1, 0.2}))
+	equals(t, []int{3}, node3.entries[0].elements)
+
+	// across the tree, collapsing what is left behind
+	node3 = o.Move(3, node3, Vector3f{0.9, 0.1, 0.9})
+	equals(t, 0, len(o.ElementsAt(Vector3f{0.2, 0.1, 0.2})))
+	equals(t, []int{3}, o.ElementsAt(Vector3f{0.9, 0.1, 0.9}))
+	equals(t, false, o.root.children[0].hasChildren)
+	checkCounts(t, o.root)
+
+	// onto a point shared with another element, using a stale node
+	equals(t, true, node1.detached)
+	node1 = o.Move(1, node1, Vector3f{0.9, 0.9, 0.9})
+	equals(t, []int{2, 1}, o.ElementsAt(Vector3f{0.9, 0.9, 0.9}))
+	equals(t, 2, o.root.count)
+
+	// onto the faces between octants
+	node1 = o.Move(1, node1, Vector3f{0.5, 0.9, 0.9})
+	equals(t, []int{1}, o.ElementsAt(Vector3f{0.5, 0.9, 0.9}))
+	node1 = o.Move(1, node1, Vector3f{0.5, 0.5, 0.5})
+	equals(t, []int{1}, o.ElementsAt(Vector3f{0.5, 0.5, 0.5}))
+	equals(t, []int{2}, o.ElementsAt(Vector3f{0.9, 0.9, 0.9}))
+	equals(t, []int{3}, o.ElementsAt(Vector3f{0.9, 0.1, 0.9}))
+
+	// not held by the node, or out of bounds
+	equals(t, true, o.Move(4, node1, Vector3f{0.5, 0.5, 0.5}) == nil)
+	equals(t, true, o.Move(1, node1, Vector3f{1.5, 0.5, 0.5}) == nil)
+	equals(t, []int{1}, o.ElementsAt(Vector3f{0.5, 0.5, 0.5}))
+}
+
+func TestMovesMatchBruteForce(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1}, WithCapacity(2))
+
+	// snap to a coarse grid so points often land on octant faces
+	randomPoint := func() Vector3f {
+		return Vector3f{float64(r.Intn(9)) / 8, float64(r.Intn(9)) / 8, float64(r.Intn(9)) / 8}
+	}
+
+	points := make([]Vector3f, 200)
+	nodes := make([]*Node[int], len(points))
+	for i := range points {
+		points[i] = randomPoint()
+		nodes[i] = o.Add(i, points[i])
+	}
+
+	for step := 0; step < 2000; step++ {
+		i := r.Intn(len(points))
+		points[i] = randomPoint()
+		nodes[i] = o.Move(i, nodes[i], points[i])
+		equals(t, false, nodes[i] == nil)
+	}
+	checkCounts(t, o.root)
+
+	for i := range points {
+		found := false
+		for _, element := range o.ElementsAt(points[i]) {
+			found = found || element == i
+		}
+		equals(t, true, found)
+	}
+
+	all := o.ElementsIn(Box{Vector3f{0, 0, 0}, Vector3f{1, 1, 1}})
+	sort.Ints(all)
+	equals(t, len(points), len(all))
+	for i := range all {
+		equals(t, i, all[i])
+	}
+}
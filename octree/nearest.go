@@ -0,0 +1,102 @@
+package octree
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// Neighbor An element found by Nearest, along with the point it was
+// added at and its distance from the query point.
+type Neighbor[T any] struct {
+	Element  T
+	Point    Vector3f
+	Distance float64
+}
+
+// Nearest Retrieves up to k elements closest to the specified point,
+// sorted by ascending distance. Elements added at the same point are
+// counted individually towards k. Elements added with AddBox are not
+// considered.
+func (o *Octree[T]) Nearest(point Vector3f, k int) []Neighbor[T] {
+	if k <= 0 {
+		return nil
+	}
+
+	return o.root.nearest(&point, k)
+}
+
+func (n *Node[T]) nearest(point *Vector3f, k int) []Neighbor[T] {
+	// branch and bound; visit nodes closest first and stop as soon as
+	// the closest unvisited box is further away than the k-th best
+	// element found so far.
+	found := &neighborHeap[T]{}
+	queue := &nodeQueue[T]{{node: n, distance: n.box.DistanceToPoint(point)}}
+
+	for queue.Len() > 0 {
+		next := heap.Pop(queue).(nodeDistance[T])
+		if found.Len() == k && next.distance > (*found)[0].Distance {
+			break
+		}
+
+		if next.node.hasChildren {
+			for _, child := range next.node.children {
+				heap.Push(queue, nodeDistance[T]{node: child, distance: child.box.DistanceToPoint(point)})
+			}
+			continue
+		}
+
+		for i := range next.node.entries {
+			e := &next.node.entries[i]
+			distance := e.point.Distance(point)
+			for _, element := range e.elements {
+				if found.Len() < k {
+					heap.Push(found, Neighbor[T]{Element: element, Point: e.point, Distance: distance})
+				} else if distance < (*found)[0].Distance {
+					(*found)[0] = Neighbor[T]{Element: element, Point: e.point, Distance: distance}
+					heap.Fix(found, 0)
+				}
+			}
+		}
+	}
+
+	neighbors := []Neighbor[T](*found)
+	sort.SliceStable(neighbors, func(i, j int) bool {
+		return neighbors[i].Distance < neighbors[j].Distance
+	})
+
+	return neighbors
+}
+
+type nodeDistance[T any] struct {
+	node     *Node[T]
+	distance float64
+}
+
+// nodeQueue is a min-heap of nodes ordered by distance.
+type nodeQueue[T any] []nodeDistance[T]
+
+func (q nodeQueue[T]) Len() int            { return len(q) }
+func (q nodeQueue[T]) Less(i, j int) bool  { return q[i].distance < q[j].distance }
+func (q nodeQueue[T]) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *nodeQueue[T]) Push(x interface{}) { *q = append(*q, x.(nodeDistance[T])) }
+func (q *nodeQueue[T]) Pop() interface{} {
+	old := *q
+	last := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return last
+}
+
+// neighborHeap is a max-heap of neighbors ordered by distance, so
+// the furthest of the best found so far is always at the top.
+type neighborHeap[T any] []Neighbor[T]
+
+func (h neighborHeap[T]) Len() int            { return len(h) }
+func (h neighborHeap[T]) Less(i, j int) bool  { return h[i].Distance > h[j].Distance }
+func (h neighborHeap[T]) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *neighborHeap[T]) Push(x interface{}) { *h = append(*h, x.(Neighbor[T])) }
+func (h *neighborHeap[T]) Pop() interface{} {
+	old := *h
+	last := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return last
+}
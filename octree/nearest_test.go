@@ -0,0 +1,59 @@
+package octree
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestNearestOrdersByDistance(t *testing.T) {
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+
+	equals(t, 0, len(o.Nearest(Vector3f{0.5, 0.5, 0.5}, 3)))
+
+	o.Add(1, Vector3f{0.1, 0.1, 0.1})
+	o.Add(2, Vector3f{0.9, 0.9, 0.9})
+	o.Add(3, Vector3f{0.4, 0.5, 0.5})
+	o.Add(4, Vector3f{0.4, 0.5, 0.5})
+
+	found := o.Nearest(Vector3f{0.5, 0.5, 0.5}, 3)
+	equals(t, 3, len(found))
+	equals(t, Vector3f{0.4, 0.5, 0.5}, found[0].Point)
+	equals(t, Vector3f{0.4, 0.5, 0.5}, found[1].Point)
+	equals(t, 2, found[2].Element)
+	equals(t, true, found[0].Distance <= found[1].Distance && found[1].Distance <= found[2].Distance)
+
+	// more requested than held
+	equals(t, 4, len(o.Nearest(Vector3f{0.5, 0.5, 0.5}, 10)))
+	equals(t, 0, len(o.Nearest(Vector3f{0.5, 0.5, 0.5}, 0)))
+
+	// query point outside the tree
+	found = o.Nearest(Vector3f{-1, -1, -1}, 1)
+	equals(t, 1, found[0].Element)
+}
+
+func TestNearestMatchesBruteForce(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+	points := make([]Vector3f, 500)
+	for i := range points {
+		points[i] = Vector3f{r.Float64(), r.Float64(), r.Float64()}
+		o.Add(i, points[i])
+	}
+
+	for q := 0; q < 20; q++ {
+		query := Vector3f{r.Float64()*1.2 - 0.1, r.Float64()*1.2 - 0.1, r.Float64()*1.2 - 0.1}
+		distances := make([]float64, len(points))
+		for i := range points {
+			distances[i] = points[i].Distance(&query)
+		}
+		sort.Float64s(distances)
+
+		found := o.Nearest(query, 10)
+		equals(t, 10, len(found))
+		for i := range found {
+			equals(t, distances[i], found[i].Distance)
+			equals(t, points[found[i].Element], found[i].Point)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+// Package obj Writes the structure of octrees as Wavefront OBJ files, for
+// viewing in tools such as Blender or MeshLab.
+package obj
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/bjnsn/go-octree/octree"
+)
+
+// Option A function that configures what Write writes.
+type Option func(*options)
+
+type options struct {
+	leavesOnly bool
+	depthLimit int
+	noPoints   bool
+}
+
+// WithLeavesOnly Writes the boxes of leaves only, rather than those of every node.
+func WithLeavesOnly() Option {
+	return func(opts *options) {
+		opts.leavesOnly = true
+	}
+}
+
+// WithDepthLimit Writes the boxes of nodes down to the depth only, the root
+// being at depth 0, so a limit of 0 writes the root alone. With
+// WithLeavesOnly, the nodes at the depth are written as if they were leaves.
+func WithDepthLimit(depth int) Option {
+	return func(opts *options) {
+		opts.depthLimit = depth
+	}
+}
+
+// WithoutPoints Leaves out the points held by the tree.
+func WithoutPoints() Option {
+	return func(opts *options) {
+		opts.noPoints = true
+	}
+}
+
+// the corners of a box differing in one coordinate, numbered
+// with the bits of their index set where they are at the max
+var edges = [12][2]int{
+	{0, 1}, {2, 3}, {4, 5}, {6, 7},
+	{0, 2}, {1, 3}, {4, 6}, {5, 7},
+	{0, 4}, {1, 5}, {2, 6}, {3, 7},
+}
+
+// Write Writes the box of each node of the tree to w as a wireframe cube of
+// 8 vertices and 12 lines, grouped into an object for each depth, followed by
+// an object holding a vertex for each distinct point in the tree.
+func Write[T any](w io.Writer, o *octree.Octree[T], opts ...Option) error {
+	cfg := options{depthLimit: -1}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	// group the boxes by depth
+	var boxes [][]octree.Box
+	var points []octree.Vector3f
+	var walk func(n *octree.Node[T], depth int)
+	walk = func(n *octree.Node[T], depth int) {
+		deep := cfg.depthLimit >= 0 && depth > cfg.depthLimit
+		if deep && cfg.noPoints {
+			return
+		}
+
+		leaf := n.IsLeaf() || depth == cfg.depthLimit
+		if !deep && (leaf || !cfg.leavesOnly) {
+			for len(boxes) <= depth {
+				boxes = append(boxes, nil)
+			}
+			boxes[depth] = append(boxes[depth], n.Bounds())
+		}
+
+		if !cfg.noPoints {
+			points = append(points, n.Points()...)
+		}
+
+		for _, child := range n.Children() {
+			walk(child, depth+1)
+		}
+	}
+	walk(o.Root(), 0)
+
+	bw := bufio.NewWriter(w)
+	var buf []byte
+	vertices := 0
+
+	for depth, atDepth := range boxes {
+		if len(atDepth) == 0 {
+			continue
+		}
+
+		buf = fmt.Appendf(buf[:0], "o depth_%d\n", depth)
+		for i := range atDepth {
+			min, max := atDepth[i].Min(), atDepth[i].Max()
+			for corner := 0; corner < 8; corner++ {
+				var v octree.Vector3f
+				for axis := 0; axis < 3; axis++ {
+					v[axis] = min[axis]
+					if corner&(1<<axis) != 0 {
+						v[axis] = max[axis]
+					}
+				}
+				buf = appendVertex(buf, &v)
+			}
+			for _, edge := range edges {
+				buf = fmt.Appendf(buf, "l %d %d\n", vertices+edge[0]+1, vertices+edge[1]+1)
+			}
+			vertices += 8
+
+			if _, err := bw.Write(buf); err != nil {
+				return err
+			}
+			buf = buf[:0]
+		}
+	}
+
+	if len(points) > 0 {
+		if _, err := io.WriteString(bw, "o points\n"); err != nil {
+			return err
+		}
+		for i := range points {
+			buf = appendVertex(buf[:0], &points[i])
+			buf = fmt.Appendf(buf, "p %d\n", vertices+1)
+			vertices++
+
+			if _, err := bw.Write(buf); err != nil {
+				return err
+			}
+		}
+	}
+
+	return bw.Flush()
+}
+
+func appendVertex(buf []byte, v *octree.Vector3f) []byte {
+	buf = append(buf, 'v')
+	for i := 0; i < 3; i++ {
+		buf = append(buf, ' ')
+		buf = strconv.AppendFloat(buf, v[i], 'g', -1, 64)
+	}
+	return append(buf, '\n')
+}
@@ -0,0 +1,79 @@
+package obj
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/bjnsn/go-octree/octree"
+	"github.com/bjnsn/go-octree/octree/internal/testutil"
+)
+
+var equals = testutil.Equals
+
+func counts(data string) map[string]int {
+	// the number of lines of each kind
+	counts := map[string]int{}
+	for _, line := range strings.Split(strings.TrimSpace(data), "\n") {
+		counts[strings.Fields(line)[0]]++
+	}
+	return counts
+}
+
+func TestWritesRoot(t *testing.T) {
+	o := octree.CreateOctree[int](octree.Vector3f{0, 0, 0}, octree.Vector3f{1, 2, 3})
+	o.Add(1, octree.Vector3f{0.5, 0.25, 1})
+	o.Add(2, octree.Vector3f{0.5, 0.25, 1})
+
+	var buf bytes.Buffer
+	equals(t, nil, Write(&buf, o))
+	equals(t, `o depth_0
+v 0 0 0
+v 1 0 0
+v 0 2 0
+v 1 2 0
+v 0 0 3
+v 1 0 3
+v 0 2 3
+v 1 2 3
+l 1 2
+l 3 4
+l 5 6
+l 7 8
+l 1 3
+l 2 4
+l 5 7
+l 6 8
+l 1 5
+l 2 6
+l 3 7
+l 4 8
+o points
+v 0.5 0.25 1
+p 9
+`, buf.String())
+}
+
+func TestWritesOptions(t *testing.T) {
+	// a root, with a branch of depth 1 and 15 leaves
+	o := octree.CreateOctree[int](octree.Vector3f{0, 0, 0}, octree.Vector3f{1, 1, 1})
+	o.Add(1, octree.Vector3f{0.1, 0.1, 0.1})
+	o.Add(2, octree.Vector3f{0.4, 0.4, 0.4})
+	o.Add(3, octree.Vector3f{0.9, 0.9, 0.9})
+
+	for _, test := range []struct {
+		opts []Option
+		exp  map[string]int
+	}{
+		{nil, map[string]int{"o": 4, "v": 17*8 + 3, "l": 17 * 12, "p": 3}},
+		{[]Option{WithLeavesOnly()}, map[string]int{"o": 3, "v": 15*8 + 3, "l": 15 * 12, "p": 3}},
+		{[]Option{WithDepthLimit(1), WithoutPoints()}, map[string]int{"o": 2, "v": 9 * 8, "l": 9 * 12}},
+		{[]Option{WithDepthLimit(0)}, map[string]int{"o": 2, "v": 8 + 3, "l": 12, "p": 3}},
+		// the nodes at the maximum depth are written as leaves
+		{[]Option{WithDepthLimit(1), WithLeavesOnly()}, map[string]int{"o": 2, "v": 8*8 + 3, "l": 8 * 12, "p": 3}},
+	} {
+		var buf bytes.Buffer
+		equals(t, nil, Write(&buf, o, test.opts...))
+		equals(t, test.exp, counts(buf.String()))
+	}
+}
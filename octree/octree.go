@@ -12,32 +12,176 @@ package octree
 import (
 	"fmt"
 	"math"
+	"reflect"
+	"strings"
 )
 
 // Octree An octree is a data structure that allows fast retrieval of elements based
-// values in three dimensions.
-type Octree struct {
-	root *Node
+// values in three dimensions. T is the type of the elements held by the tree.
+type Octree[T any] struct {
+	root     *Node[T]
+	equals   func(a, b T) bool
+	capacity int
+	maxDepth int
+	growth   bool
+	// parallelism is the number of goroutines a query may use.
+	parallelism int
+	// codec encodes elements for WriteTo and ReadFrom; nil
+	// when T has no default encoding and none was given.
+	codec Codec[T]
+	// looseness is the factor each node's box is scaled by
+	// about its center to give the bounds of its volumes.
+	looseness float64
+	// gen identifies the nodes this version of the tree may modify
+	// in place; the rest are shared with a snapshot (see Snapshot).
+	gen uint64
+}
+
+// Option Configures an octree when passed to CreateOctree.
+type Option func(*options)
+
+type options struct {
+	// equals is a func(a, b T) bool and codec a Codec[T]; they are held
+	// as interfaces so that Option itself does not need a type parameter.
+	equals interface{}
+	codec  interface{}
+
+	capacity int
+	maxDepth int
+	growth   bool
+
+	looseness   float64
+	parallelism int
+}
+
+// WithEquals Sets the function used to compare elements when removing
+// them from the tree. By default elements are compared with ==, or with
+// reflect.DeepEqual when they are not comparable (e.g. slices or maps).
+// As Option is not generic, the type of equals is only checked when the
+// tree is created: CreateOctree panics unless it is func(a, b T) bool.
+func WithEquals[T any](equals func(a, b T) bool) Option {
+	return func(opts *options) {
+		opts.equals = equals
+	}
+}
+
+// WithCapacity Sets the number of distinct points (and volumes, see AddBox)
+// a leaf can hold before it is subdivided. Defaults to 1; values less than 1 are treated as 1.
+func WithCapacity(capacity int) Option {
+	return func(opts *options) {
+		opts.capacity = capacity
+	}
+}
+
+// WithMaxDepth Sets the depth (the root being at depth 0) beyond which nodes
+// are no longer subdivided; leaves at this depth simply hold as many distinct
+// points as are added to them. Defaults to 0, meaning there is no limit, in which
+// case nearly-coincident points can produce extremely deep trees.
+func WithMaxDepth(depth int) Option {
+	return func(opts *options) {
+		opts.maxDepth = depth
+	}
 }
 
 // CreateOctree Makes a new octree with the given min and max.
-func CreateOctree(min, max Vector3f) *Octree {
-	mn := min.Min(&max)
-	mx := min.Max(&max)
-	o := Octree{}
-	o.root = &Node{box: Box{min: mn, max: mx}}
+func CreateOctree[T any](min, max Vector3f, opts ...Option) *Octree[T] {
+	cfg := options{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	o := Octree[T]{equals: defaultEquals[T](), capacity: 1, looseness: 1, gen: nextGeneration()}
+	o.root = &Node[T]{box: NewBox(min, max), gen: o.gen}
+
+	if cfg.equals != nil {
+		equals, ok := cfg.equals.(func(a, b T) bool)
+		if !ok {
+			panic(fmt.Sprintf("octree: WithEquals given %T, expected func(a, b %v) bool", cfg.equals, reflect.TypeFor[T]()))
+		}
+		o.equals = equals
+	}
+
+	o.codec = defaultCodec[T]()
+	if cfg.codec != nil {
+		codec, ok := cfg.codec.(Codec[T])
+		if !ok {
+			panic(fmt.Sprintf("octree: WithCodec given %T, expected an octree.Codec[%v]", cfg.codec, reflect.TypeFor[T]()))
+		}
+		o.codec = codec
+	}
+
+	if cfg.capacity > 1 {
+		o.capacity = cfg.capacity
+	}
+
+	if cfg.maxDepth > 0 {
+		o.maxDepth = cfg.maxDepth
+	}
+
+	o.growth = cfg.growth
+
+	if cfg.looseness > 1 {
+		o.looseness = cfg.looseness
+	}
+
+	if cfg.parallelism > 1 {
+		o.parallelism = cfg.parallelism
+	}
+
 	return &o
 }
 
+func defaultEquals[T any]() func(a, b T) bool {
+	// == on an interface holding a non-comparable value panics,
+	// so fall back to a deep comparison for those types, or for
+	// those values when T has interfaces that may hold them.
+	t := reflect.TypeFor[T]()
+	if !t.Comparable() {
+		return func(a, b T) bool {
+			return reflect.DeepEqual(a, b)
+		}
+	}
+
+	if holdsInterface(t) {
+		return func(a, b T) bool {
+			if reflect.ValueOf(a).Comparable() && reflect.ValueOf(b).Comparable() {
+				return interface{}(a) == interface{}(b)
+			}
+			return reflect.DeepEqual(a, b)
+		}
+	}
+
+	return func(a, b T) bool {
+		return interface{}(a) == interface{}(b)
+	}
+}
+
+func holdsInterface(t reflect.Type) bool {
+	// whether t is an interface, or an array or struct holding one
+	switch t.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.Array:
+		return holdsInterface(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if holdsInterface(t.Field(i).Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // Clear Removes all the data from the Octree while
 // retaining its bounding box. Returns true if octree is ready for use
 // (because it has previously been initialized).
-func (o *Octree) Clear() bool {
+func (o *Octree[T]) Clear() bool {
 	if o.root != nil {
 		// if octree has been initializes, use the same box,
 		// but create a new root, freeing the other memory
 		// (except where outside references have been retained).
-		o.root = &Node{box: o.root.box}
+		o.root = &Node[T]{box: o.root.box, gen: o.gen}
 		return true
 	}
 
@@ -46,43 +190,116 @@ func (o *Octree) Clear() bool {
 
 // Add Inserts the element in the tree at the specified point.
 // If you may need to remove the element later, retain the
-// returned node for fast removal.
-func (o *Octree) Add(element interface{}, point Vector3f) *Node {
-	return o.root.tryAdd([]interface{}{element}, &point)
+// returned node for fast removal. Returns nil if the point is outside
+// the tree, unless it was created using WithGrowth. Use AddE to find
+// out why an element was rejected.
+func (o *Octree[T]) Add(element T, point Vector3f) *Node[T] {
+	node, _ := o.AddE(element, point)
+	return node
+}
+
+// AddE Inserts the element in the tree at the specified point as Add does,
+// but returns a *PointError wrapping ErrInvalidPoint, ErrOutOfBounds or
+// ErrInternal when the element can't be added.
+func (o *Octree[T]) AddE(element T, point Vector3f) (*Node[T], error) {
+	if err := checkPoint(&point); err != nil {
+		return nil, err
+	}
+
+	if o.growth {
+		if err := o.grow(&point); err != nil {
+			return nil, err
+		}
+	}
+
+	if !o.root.box.ContainsPoint(&point) {
+		return nil, &PointError{Point: point, Err: ErrOutOfBounds}
+	}
+
+	node := o.writable(o.root).tryAdd(o, 0, []T{element}, &point)
+	if node == nil {
+		// box.contains evaluated to true, but none of the children added the point
+		return nil, &PointError{Point: point, Err: ErrInternal}
+	}
+
+	return node, nil
 }
 
 // ElementsAt Retrieves a slice of elements that exist at
 // the specified point in the tree.
-func (o *Octree) ElementsAt(point Vector3f) []interface{} {
+func (o *Octree[T]) ElementsAt(point Vector3f) []T {
 	return o.root.elementsAt(&point)
 }
 
 // ElementsIn Retrieves a slice of element that exist
-// within the specified box.
-func (o *Octree) ElementsIn(box Box) []interface{} {
-	return o.root.elementsIn(&box)
+// within the specified box, or nil if there are none.
+func (o *Octree[T]) ElementsIn(box Box) []T {
+	return o.root.elementsIn(o, &box, o.fanOut(), nil)
 }
 
 // Remove Removes the specified element from the tree.
 // Generally, RemoveUsing should used as it is faster under
 // most circumstances.
-func (o *Octree) Remove(element interface{}) bool {
-	return o.root.remove(element)
+func (o *Octree[T]) Remove(element T) bool {
+	node := o.remove(o.root, nil, element, true, true)
+	if node == nil {
+		return false
+	}
+
+	o.collapse(node)
+	return true
 }
 
 // RemoveUsing Removes the specified element from the tree; node constrains the search
 // for the element and should usually be the node returned when this element
-// was placed in the tree using Add()
-func (o *Octree) RemoveUsing(element interface{}, node *Node) bool {
-	if node != nil {
-		return node.remove(element)
+// was placed in the tree using Add() or AddBox()
+func (o *Octree[T]) RemoveUsing(element T, node *Node[T]) bool {
+	// nodes that have since been collapsed into an
+	// ancestor forward the search to that ancestor.
+	node, region := o.resolve(node)
+	if node == nil {
+		return false
 	}
-	return false
+
+	removedFrom := o.remove(node, region, element, true, true)
+	if removedFrom == nil {
+		return false
+	}
+
+	o.collapse(removedFrom)
+	return true
+}
+
+func (o *Octree[T]) collapse(node *Node[T]) {
+	// after a removal, turn the highest ancestor of node whose
+	// subtree now holds few enough points and volumes for a single
+	// leaf back into a leaf, so that the tree doesn't only ever grow.
+
+	var top *Node[T]
+	for n := node; n != nil && n.count <= o.capacity; n = n.parent {
+		top = n
+	}
+
+	if top == nil || !top.hasChildren {
+		return
+	}
+
+	for _, child := range top.children {
+		child.detachInto(o, top)
+	}
+
+	top.hasChildren = false
+	top.children = nil
+}
+
+// Root Returns the root node of the tree, which holds the rest.
+func (o *Octree[T]) Root() *Node[T] {
+	return o.root
 }
 
 // ToString Get a human readable representation of the state of
 // this octree and its contents.
-func (o *Octree) ToString() string {
+func (o *Octree[T]) ToString() string {
 	str := "nil"
 	if o.root != nil {
 		str = o.root.recursiveToString("  ", "  ")
@@ -91,17 +308,37 @@ func (o *Octree) ToString() string {
 	return fmt.Sprintf("Octree{\n  root: %v\n}", str)
 }
 
-// Node An element within the tree that can either act as a leaf, that can directly hold a point
-// and its corresponding elements or act as a branch and hold references to child nodes.
-type Node struct {
+// Node An element within the tree that can either act as a leaf, that can directly hold points
+// and their corresponding elements or act as a branch and hold references to child nodes.
+// Either can also hold elements with an extent (volumes) that fit within its box.
+type Node[T any] struct {
 	box         Box
-	point       *Vector3f
-	elements    []interface{}
+	parent      *Node[T]
+	entries     []entry[T]
+	volumes     []volume[T]
 	hasChildren bool
-	children    []*Node
+	children    []*Node[T]
+	// count is the number of distinct points and volumes held by this node and its descendants.
+	count int
+	// detached is set once this node has been collapsed into its parent.
+	detached bool
+	// gen is the generation of the tree that created this node.
+	gen uint64
+}
+
+// entry A distinct point held by a leaf and the elements added at it.
+type entry[T any] struct {
+	point    Vector3f
+	elements []T
 }
 
-func (n *Node) tryAdd(elements []interface{}, point *Vector3f) *Node {
+// volume An element added with the extent of a box.
+type volume[T any] struct {
+	box     Box
+	element T
+}
+
+func (n *Node[T]) tryAdd(o *Octree[T], depth int, elements []T, point *Vector3f) *Node[T] {
 	// attempt to add the elements in this node (or a descendant)
 	// at the specified point.
 
@@ -110,36 +347,61 @@ func (n *Node) tryAdd(elements []interface{}, point *Vector3f) *Node {
 	}
 
 	if n.hasChildren {
-		return n.addToChildren(elements, point)
+		return n.addToChildren(o, depth, elements, point)
 	}
 
-	if n.point != nil {
-		// leaf already has assigned point
-		if *n.point == *point {
+	for i := range n.entries {
+		if n.entries[i].point == *point {
 			// points are equal
-			n.elements = append(n.elements, elements...)
+			n.entries[i].elements = append(n.entries[i].elements, elements...)
 			return n
 		}
+	}
 
-		// subdivide because points are different
-		return n.subdivide(elements, point)
+	if n.load() >= o.capacity && o.canSubdivide(n, depth) {
+		// subdivide because leaf is full of different points
+		n.subdivide(o)
+		return n.addToChildren(o, depth, elements, point)
 	}
 
-	// set own elements and point
-	n.elements = elements
-	n.point = point
+	// hold elements and point in own bucket
+	n.entries = append(n.entries, entry[T]{point: *point, elements: elements})
+	n.adjustCount(1)
 
 	return n
 }
 
-func (n *Node) addToChildren(elements []interface{}, point *Vector3f) *Node {
+func (n *Node[T]) load() int {
+	// the number of distinct points and volumes held directly by this node
+	return len(n.entries) + len(n.volumes)
+}
+
+func (o *Octree[T]) canSubdivide(n *Node[T], depth int) bool {
+	return (o.maxDepth == 0 || depth < o.maxDepth) && n.box.canSplit()
+}
+
+func (n *Node[T]) adjustCount(delta int) {
+	// update the number of distinct points in this node and its ancestors
+	for ; n != nil; n = n.parent {
+		n.count += delta
+	}
+}
+
+func (n *Node[T]) childContaining(point *Vector3f) *Node[T] {
 	for _, child := range n.children {
-		// try adding to child
-		leaf := child.tryAdd(elements, point)
+		if child.box.ContainsPoint(point) {
+			return child
+		}
+	}
+
+	return nil
+}
 
-		if leaf != nil {
-			// succeeded adding
-			return leaf
+func (n *Node[T]) addToChildren(o *Octree[T], depth int, elements []T, point *Vector3f) *Node[T] {
+	for i, child := range n.children {
+		if child.box.ContainsPoint(point) {
+			// add to the child, copying it first if shared with a snapshot
+			return o.ownChild(n, i).tryAdd(o, depth+1, elements, point)
 		}
 	}
 
@@ -147,30 +409,44 @@ func (n *Node) addToChildren(elements []interface{}, point *Vector3f) *Node {
 	return nil
 }
 
-func (n *Node) subdivide(addElements []interface{}, atPoint *Vector3f) *Node {
+func (n *Node[T]) subdivide(o *Octree[T]) {
 	// create child nodes for what is currently a leaf,
-	// moving its current contents to one of those leafs.
+	// moving its current contents to those leafs.
 
 	// setup this node's children
 	n.hasChildren = true
 	subBoxes := n.box.makeSubBoxes()
 
 	for i := 0; i < 8; i++ {
-		n.children = append(n.children, &Node{box: subBoxes[i]})
+		n.children = append(n.children, &Node[T]{box: subBoxes[i], parent: n, gen: o.gen})
 	}
 
-	// add node's elements and point to a child
-	n.addToChildren(n.elements, n.point)
-
-	// clear elements and point from self
-	n.elements = nil
-	n.point = nil
-
-	// add the new element to a child
-	return n.addToChildren(addElements, atPoint)
+	// move node's elements and points to children; there are
+	// never more than a child's capacity so they can be placed
+	// directly, leaving this node's count unchanged.
+	for i := range n.entries {
+		child := n.childContaining(&n.entries[i].point)
+		child.entries = append(child.entries, n.entries[i])
+		child.count++
+	}
+	n.entries = nil
+
+	// likewise for volumes that fit within a child; volumes
+	// straddling the children remain with this node.
+	straddling := n.volumes[:0]
+	for _, v := range n.volumes {
+		if i := o.childFor(n, &v.box); i >= 0 {
+			child := n.children[i]
+			child.volumes = append(child.volumes, v)
+			child.count++
+		} else {
+			straddling = append(straddling, v)
+		}
+	}
+	n.volumes = straddling
 }
 
-func (n *Node) elementsAt(point *Vector3f) []interface{} {
+func (n *Node[T]) elementsAt(point *Vector3f) []T {
 	// get any alements in this node (or a descendant)
 	// at the specified point
 
@@ -182,78 +458,296 @@ func (n *Node) elementsAt(point *Vector3f) []interface{} {
 		}
 	} else {
 		// when a leaf
-		if n.point != nil && *point == *n.point {
-			return n.elements
+		for i := range n.entries {
+			if n.entries[i].point == *point {
+				return n.entries[i].elements
+			}
 		}
 	}
 
 	return nil
 }
 
-func (n *Node) elementsIn(box *Box) []interface{} {
-	// get any alements in this node (or a descendant)
-	// within the specified box
+func (n *Node[T]) elementsIn(o *Octree[T], box *Box, f *fanOut, elements []T) []T {
+	// append any elements in this node (or a descendant)
+	// within the specified box to elements
+
+	for i := range n.volumes {
+		if n.volumes[i].box.Intersects(box) {
+			elements = append(elements, n.volumes[i].element)
+		}
+	}
 
 	if n.hasChildren {
-		elements := []interface{}{}
+		if f.splits(n.count) {
+			return fanOutChildren(f, n, elements, func(child *Node[T], elements []T) []T {
+				return child.childElementsIn(o, box, f, elements)
+			})
+		}
 
 		for _, child := range n.children {
-			if child.box.IsContainedIn(box) {
-				// fully contained
-				elements = append(elements, child.elementsIn(&child.box)...)
-			} else if child.box.Contains(box) || child.box.Intersects(box) {
-				// partially contained
-				elements = append(elements, child.elementsIn(box)...)
-			}
+			elements = child.childElementsIn(o, box, f, elements)
 		}
-
 		return elements
 	}
 
 	// when a leaf
-	if n.point != nil && box.ContainsPoint(n.point) {
-		return n.elements
+	for i := range n.entries {
+		if box.ContainsPoint(&n.entries[i].point) {
+			elements = append(elements, n.entries[i].elements...)
+		}
 	}
 
-	return nil
+	return elements
 }
 
-func (n *Node) remove(element interface{}) bool {
-	// remove the first instance of the specified element
-	// in this node (or in a descendant)
+func (n *Node[T]) childElementsIn(o *Octree[T], box *Box, f *fanOut, elements []T) []T {
+	// append any elements within the box in this child of a branch being searched
+	if n.box.IsContainedIn(box) {
+		// fully contained
+		return n.appendAll(f, elements)
+	} else if bounds := o.looseBox(n); bounds.Contains(box) || bounds.Intersects(box) {
+		// partially contained
+		return n.elementsIn(o, box, f, elements)
+	}
+
+	return elements
+}
+
+func (n *Node[T]) appendAll(f *fanOut, elements []T) []T {
+	// append all the elements in this node and its descendants
+
+	for i := range n.volumes {
+		elements = append(elements, n.volumes[i].element)
+	}
+
+	for i := range n.entries {
+		elements = append(elements, n.entries[i].elements...)
+	}
+
+	if f.splits(n.count) {
+		return fanOutChildren(f, n, elements, func(child *Node[T], elements []T) []T {
+			return child.appendAll(f, elements)
+		})
+	}
+
+	for _, child := range n.children {
+		elements = child.appendAll(f, elements)
+	}
+
+	return elements
+}
+
+func (o *Octree[T]) remove(node *Node[T], region *Box, element T, points, volumes bool) *Node[T] {
+	// remove the first instance of the specified element held
+	// by node (or a descendant) as a point and/or as a volume,
+	// within region if not nil, returning the node of this
+	// tree it was removed from.
+
+	found := node.find(o, element, points, volumes, region)
+	if found == nil {
+		return nil
+	}
+
+	found = o.writable(found)
+	found.removeHeld(element, o.equals, points, volumes)
+	return found
+}
+
+func (n *Node[T]) find(o *Octree[T], element T, points, volumes bool, region *Box) *Node[T] {
+	// find the node holding the first instance of the specified
+	// element in this node (or in a descendant). When region is not
+	// nil, only points within it and volumes overlapping it are considered.
+
+	if volumes {
+		for i := range n.volumes {
+			if o.equals(n.volumes[i].element, element) && (region == nil || n.volumes[i].box.Intersects(region)) {
+				return n
+			}
+		}
+	}
 
 	if n.hasChildren {
 		for _, child := range n.children {
-			if child.remove(element) {
+			if region != nil {
+				if bounds := o.looseBox(child); !bounds.Intersects(region) {
+					continue
+				}
+			}
+
+			if found := child.find(o, element, points, volumes, region); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+
+	if points {
+		for i := range n.entries {
+			if region != nil && !region.ContainsPoint(&n.entries[i].point) {
+				continue
+			}
+
+			for _, val := range n.entries[i].elements {
+				if o.equals(val, element) {
+					return n
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (n *Node[T]) removeHeld(element T, equals func(a, b T) bool, points, volumes bool) bool {
+	// remove the first instance of the specified element
+	// held directly by this node.
+
+	if volumes {
+		for i := range n.volumes {
+			if equals(n.volumes[i].element, element) {
+				n.volumes = append(n.volumes[:i], n.volumes[i+1:]...)
+				n.adjustCount(-1)
 				return true
 			}
 		}
+	}
+
+	if !points {
 		return false
 	}
 
-	for idx, val := range n.elements {
-		if val == element {
-			// remove element from the slice
-			n.elements = append(n.elements[:idx], n.elements[idx+1:]...)
-			return true
+	for i := range n.entries {
+		for idx, val := range n.entries[i].elements {
+			if equals(val, element) {
+				// remove element from the slice
+				n.entries[i].elements = append(n.entries[i].elements[:idx], n.entries[i].elements[idx+1:]...)
+
+				if len(n.entries[i].elements) == 0 {
+					// forget the point once nothing is left at it
+					n.entries = append(n.entries[:i], n.entries[i+1:]...)
+					n.adjustCount(-1)
+				}
+				return true
+			}
 		}
 	}
 	return false
 }
 
+func (n *Node[T]) detachInto(o *Octree[T], top *Node[T]) {
+	// move the points and volumes of this node and its descendants
+	// into top and mark them all as detached from the tree.
+
+	if n.gen != o.gen {
+		// shared with a snapshot, so copy rather than move
+		top.entries = append(top.entries, cloneEntries(n.entries)...)
+		top.volumes = append(top.volumes, n.volumes...)
+		for _, child := range n.children {
+			child.detachInto(o, top)
+		}
+		return
+	}
+
+	top.entries = append(top.entries, n.entries...)
+	top.volumes = append(top.volumes, n.volumes...)
+	for _, child := range n.children {
+		child.detachInto(o, top)
+	}
+
+	n.entries = nil
+	n.volumes = nil
+	n.hasChildren = false
+	n.children = nil
+	n.count = 0
+	n.detached = true
+}
+
+// Bounds Returns the box of the node.
+func (n *Node[T]) Bounds() Box {
+	return n.box
+}
+
+// Point Returns the point held by the node when it holds exactly one
+// distinct point, as leaves do by default (see WithCapacity).
+func (n *Node[T]) Point() (Vector3f, bool) {
+	if len(n.entries) != 1 {
+		return Vector3f{}, false
+	}
+
+	return n.entries[0].point, true
+}
+
+// Points Returns the distinct points held by the node; only leaves hold points.
+func (n *Node[T]) Points() []Vector3f {
+	var points []Vector3f
+	for i := range n.entries {
+		points = append(points, n.entries[i].point)
+	}
+
+	return points
+}
+
+// Elements Returns the elements held by the node itself, rather than its
+// descendants; those at its points, in the order of Points, followed by
+// those added with AddBox.
+func (n *Node[T]) Elements() []T {
+	var elements []T
+	for i := range n.entries {
+		elements = append(elements, n.entries[i].elements...)
+	}
+	for i := range n.volumes {
+		elements = append(elements, n.volumes[i].element)
+	}
+
+	return elements
+}
+
+// Children Returns the eight children of a branch, or nil for a leaf.
+func (n *Node[T]) Children() []*Node[T] {
+	if !n.hasChildren {
+		return nil
+	}
+
+	return append([]*Node[T](nil), n.children...)
+}
+
+// IsLeaf Returns whether the node is a leaf, rather than a branch.
+func (n *Node[T]) IsLeaf() bool {
+	return !n.hasChildren
+}
+
+// Parent Returns the parent of the node, or nil for the root. A node shared
+// between versions of the tree (see Snapshot) returns its parent in the
+// version it was made in.
+func (n *Node[T]) Parent() *Node[T] {
+	return n.parent
+}
+
+// Depth Returns the number of ancestors of the node, the root being at depth 0.
+// Like Parent, it describes a shared node in the version it was made in.
+func (n *Node[T]) Depth() int {
+	depth := 0
+	for p := n.parent; p != nil; p = p.parent {
+		depth++
+	}
+	return depth
+}
+
 // ToString Get a human readable representation of the state of
 // this node and its contents.
-func (n *Node) ToString() string {
+func (n *Node[T]) ToString() string {
 	return n.recursiveToString("", "  ")
 }
 
-func (n *Node) recursiveToString(curIndent, stepIndent string) string {
+func (n *Node[T]) recursiveToString(curIndent, stepIndent string) string {
 	singleIndent := curIndent + stepIndent
 
 	// default values
 	childStr := "nil"
 	pointStr := "nil"
 	elementStr := "nil"
+	volumeStr := "nil"
 
 	if n.hasChildren {
 		doubleIndent := singleIndent + stepIndent
@@ -267,16 +761,29 @@ func (n *Node) recursiveToString(curIndent, stepIndent string) string {
 		childStr = fmt.Sprintf("[\n%v%v]", childStr, singleIndent)
 	}
 
-	if n.point != nil {
-		pointStr = n.point.ToString()
+	if n.entries != nil {
+		// not stringifying elements since their type is unknown
+		pointStrs := make([]string, len(n.entries))
+		elementStrs := make([]string, len(n.entries))
+		for i := range n.entries {
+			pointStrs[i] = n.entries[i].point.ToString()
+			elementStrs[i] = fmt.Sprintf("[%d]", len(n.entries[i].elements))
+		}
+
+		pointStr = fmt.Sprintf("[%v]", strings.Join(pointStrs, ", "))
+		elementStr = fmt.Sprintf("[%v]", strings.Join(elementStrs, ", "))
 	}
 
-	if n.elements != nil {
-		// not stringifying elements since their type is unknown
-		elementStr = fmt.Sprintf("[%d]", len(n.elements))
+	if n.volumes != nil {
+		volumeStrs := make([]string, len(n.volumes))
+		for i := range n.volumes {
+			volumeStrs[i] = n.volumes[i].box.ToString()
+		}
+
+		volumeStr = fmt.Sprintf("[%v]", strings.Join(volumeStrs, ", "))
 	}
 
-	return fmt.Sprintf("Node{\n%vchildren: %v,\n%vbox: %v,\n%vpoint: %v\n%velements: %v,\n%v}", singleIndent, childStr, singleIndent, n.box.ToString(), singleIndent, pointStr, singleIndent, elementStr, curIndent)
+	return fmt.Sprintf("Node{\n%vchildren: %v,\n%vbox: %v,\n%vpoints: %v\n%velements: %v,\n%vvolumes: %v,\n%v}", singleIndent, childStr, singleIndent, n.box.ToString(), singleIndent, pointStr, singleIndent, elementStr, singleIndent, volumeStr, curIndent)
 }
 
 // Box Defines an axis aligned rectangular solid.
@@ -285,13 +792,28 @@ type Box struct {
 	max Vector3f
 }
 
+// NewBox Makes a box with the given corners, which may be in any order.
+func NewBox(min, max Vector3f) Box {
+	return Box{min: min.Min(&max), max: min.Max(&max)}
+}
+
+// Min Returns the corner of the box with the least coordinates.
+func (b Box) Min() Vector3f {
+	return b.min
+}
+
+// Max Returns the corner of the box with the greatest coordinates.
+func (b Box) Max() Vector3f {
+	return b.max
+}
+
 // Size Returns the dimensions of the Box.
-func (b *Box) Size() Vector3f {
+func (b Box) Size() Vector3f {
 	return b.max.Minus(&b.min)
 }
 
 // ContainsPoint Returns whether the specified point is contained in this box.
-func (b *Box) ContainsPoint(v *Vector3f) bool {
+func (b Box) ContainsPoint(v *Vector3f) bool {
 	return (b.min[0] <= v[0] &&
 		b.max[0] >= v[0] &&
 		b.min[1] <= v[1] &&
@@ -301,7 +823,7 @@ func (b *Box) ContainsPoint(v *Vector3f) bool {
 }
 
 // Contains Returns whether the specified box is contained in this box.
-func (b *Box) Contains(o *Box) bool {
+func (b Box) Contains(o *Box) bool {
 	return (b.min[0] <= o.min[0] &&
 		b.max[0] >= o.max[0] &&
 		b.min[1] <= o.min[1] &&
@@ -311,13 +833,13 @@ func (b *Box) Contains(o *Box) bool {
 }
 
 // IsContainedIn Returns whether the specified box contains this box.
-func (b *Box) IsContainedIn(o *Box) bool {
-	return o.Contains(b)
+func (b Box) IsContainedIn(o *Box) bool {
+	return o.Contains(&b)
 }
 
 // Intersects Returns whether any portion of this box intersects with
 // the specified box.
-func (b *Box) Intersects(o *Box) bool {
+func (b Box) Intersects(o *Box) bool {
 	return !(b.max[0] < o.min[0] ||
 		o.max[0] < b.min[0] ||
 		b.max[1] < o.min[1] ||
@@ -326,13 +848,80 @@ func (b *Box) Intersects(o *Box) bool {
 		o.max[2] < b.min[2])
 }
 
+// IntersectsRay Returns whether the ray from origin in direction dir
+// passes through this box, along with the distances (in multiples of dir)
+// at which it enters and leaves the box. The distances may be negative
+// when the box is behind the origin.
+func (b Box) IntersectsRay(origin, dir *Vector3f) (float64, float64, bool) {
+	near := math.Inf(-1)
+	far := math.Inf(1)
+
+	for i := 0; i < 3; i++ {
+		if dir[i] == 0 {
+			// parallel to the slab, so must start within it
+			if origin[i] < b.min[i] || origin[i] > b.max[i] {
+				return 0, 0, false
+			}
+			continue
+		}
+
+		t0 := (b.min[i] - origin[i]) / dir[i]
+		t1 := (b.max[i] - origin[i]) / dir[i]
+		if t0 > t1 {
+			t0, t1 = t1, t0
+		}
+
+		near = math.Max(near, t0)
+		far = math.Min(far, t1)
+		if near > far {
+			return 0, 0, false
+		}
+	}
+
+	return near, far, true
+}
+
+// DistanceToPoint Returns the distance from the specified point to the
+// closest point in this box; zero when the point is contained in the box.
+func (b Box) DistanceToPoint(v *Vector3f) float64 {
+	closest := v.Max(&b.min)
+	closest = closest.Min(&b.max)
+	return closest.Distance(v)
+}
+
+// MaxDistanceToPoint Returns the distance from the specified point to the
+// furthest point (corner) of this box.
+func (b Box) MaxDistanceToPoint(v *Vector3f) float64 {
+	furthest := Vector3f{}
+	for i := 0; i < 3; i++ {
+		if math.Abs(v[i]-b.min[i]) > math.Abs(v[i]-b.max[i]) {
+			furthest[i] = b.min[i]
+		} else {
+			furthest[i] = b.max[i]
+		}
+	}
+	return furthest.Distance(v)
+}
+
 // ToString Get a human readable representation of the state of
 // this box.
-func (b *Box) ToString() string {
+func (b Box) ToString() string {
 	return fmt.Sprintf("Box{min: %v, max: %v}", b.min.ToString(), b.max.ToString())
 }
 
-func (b *Box) makeSubBoxes() [8]Box {
+func (b Box) canSplit() bool {
+	// whether the box is large enough that its child
+	// boxes will be smaller than it in some dimension.
+	center := b.min.Lerp(&b.max, 0.5)
+	for i := 0; i < 3; i++ {
+		if b.min[i] < center[i] && center[i] < b.max[i] {
+			return true
+		}
+	}
+	return false
+}
+
+func (b Box) makeSubBoxes() [8]Box {
 	// gets the child boxes (octants) of the box.
 	center := b.min.Lerp(&b.max, 0.5)
 
@@ -352,23 +941,23 @@ func (b *Box) makeSubBoxes() [8]Box {
 type Vector3f [3]float64
 
 // Minus Subtracts another Vector3f from this Vector3f and returns the result.
-func (v *Vector3f) Minus(other *Vector3f) Vector3f {
+func (v Vector3f) Minus(other *Vector3f) Vector3f {
 	return Vector3f{v[0] - other[0], v[1] - other[1], v[2] - other[2]}
 }
 
 // Plus Returns the addition of the Vector3f(s).
-func (v *Vector3f) Plus(other *Vector3f) Vector3f {
+func (v Vector3f) Plus(other *Vector3f) Vector3f {
 	return Vector3f{v[0] + other[0], v[1] + other[1], v[2] + other[2]}
 }
 
 // Scale Returns the multiplication of the Vector3f by a number.
-func (v *Vector3f) Scale(f float64) Vector3f {
+func (v Vector3f) Scale(f float64) Vector3f {
 	return Vector3f{v[0] * f, v[1] * f, v[2] * f}
 }
 
 // Min Returns the a vector where each component is the lesser of the
 // corresponding component in this and the specified vector.
-func (v *Vector3f) Min(other *Vector3f) Vector3f {
+func (v Vector3f) Min(other *Vector3f) Vector3f {
 	return Vector3f{
 		math.Min(v[0], other[0]),
 		math.Min(v[1], other[1]),
@@ -378,7 +967,7 @@ func (v *Vector3f) Min(other *Vector3f) Vector3f {
 
 // Max Returns the a vector where each component is the greater of the
 // corresponding component in this and the specified vector.
-func (v *Vector3f) Max(other *Vector3f) Vector3f {
+func (v Vector3f) Max(other *Vector3f) Vector3f {
 	return Vector3f{
 		math.Max(v[0], other[0]),
 		math.Max(v[1], other[1]),
@@ -387,7 +976,7 @@ func (v *Vector3f) Max(other *Vector3f) Vector3f {
 }
 
 // Lerp Returns the linear interpolation between two Vector3f(s).
-func (v *Vector3f) Lerp(other *Vector3f, f float64) Vector3f {
+func (v Vector3f) Lerp(other *Vector3f, f float64) Vector3f {
 	return Vector3f{
 		(other[0]-v[0])*f + v[0],
 		(other[1]-v[1])*f + v[1],
@@ -395,8 +984,24 @@ func (v *Vector3f) Lerp(other *Vector3f, f float64) Vector3f {
 	}
 }
 
+// Dot Returns the dot product of the Vector3f(s).
+func (v Vector3f) Dot(other *Vector3f) float64 {
+	return v[0]*other[0] + v[1]*other[1] + v[2]*other[2]
+}
+
+// Length Returns the euclidean length of the Vector3f.
+func (v Vector3f) Length() float64 {
+	return math.Sqrt(v[0]*v[0] + v[1]*v[1] + v[2]*v[2])
+}
+
+// Distance Returns the euclidean distance between two Vector3f(s).
+func (v Vector3f) Distance(other *Vector3f) float64 {
+	d := v.Minus(other)
+	return d.Length()
+}
+
 // ToString Get a human readable representation of the state of
 // this vector.
-func (v *Vector3f) ToString() string {
+func (v Vector3f) ToString() string {
 	return fmt.Sprintf("Vector3f{%f, %f, %f}", v[0], v[1], v[2])
 }
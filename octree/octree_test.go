@@ -2,6 +2,8 @@ package octree
 
 import (
 	"fmt"
+	"math"
+	"math/rand"
 	"path/filepath"
 	"reflect"
 	"runtime"
@@ -139,11 +141,11 @@ func TestBoxIntersectsBox(t *testing.T) {
 }
 
 func TestInitializesRoot(t *testing.T) {
-	o := CreateOctree(Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
 
 	//o.Add(99, Vector3f{10, 0, 0})
 
-	equals(t, true, o.root.point == nil)
+	equals(t, true, len(o.root.entries) == 0)
 	equals(t, Vector3f{0, 0, 0}, o.root.box.min)
 	equals(t, Vector3f{1, 1, 1}, o.root.box.max)
 	equals(t, false, o.root.hasChildren)
@@ -151,40 +153,40 @@ func TestInitializesRoot(t *testing.T) {
 }
 
 func TestInsertsContainedElements(t *testing.T) {
-	o := CreateOctree(Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
 
 	equals(t, true, o.Add(99, Vector3f{1.00000000001, 1, 1}) == nil)
 	equals(t, false, o.root.hasChildren)
 	equals(t, true, o.root.children == nil)
-	equals(t, true, o.root.point == nil)
+	equals(t, true, len(o.root.entries) == 0)
 
 	equals(t, true, o.Add(99, Vector3f{-0.0000000001, 0, 0}) == nil)
 	equals(t, false, o.root.hasChildren)
 	equals(t, true, o.root.children == nil)
-	equals(t, true, o.root.point == nil)
+	equals(t, true, len(o.root.entries) == 0)
 
 	equals(t, false, o.Add(88, Vector3f{0.5, 0, 0}) == nil)
 	equals(t, false, o.root.hasChildren)
 	equals(t, true, o.root.children == nil)
-	equals(t, false, o.root.point == nil)
+	equals(t, false, len(o.root.entries) == 0)
 }
 
 func TestEqualPointsSubdivide(t *testing.T) {
-	o := CreateOctree(Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
 
 	o.Add(1, Vector3f{0, 0, 0})
 	o.Add(1, Vector3f{0, 0, 0})
 	equals(t, false, o.root.hasChildren)
 	equals(t, true, o.root.children == nil)
-	equals(t, true, *o.root.point == Vector3f{0, 0, 0})
+	equals(t, true, o.root.entries[0].point == Vector3f{0, 0, 0})
 	o.Add(1, Vector3f{1, 1, 1})
 	equals(t, true, o.root.hasChildren)
 	equals(t, false, o.root.children == nil)
-	equals(t, true, o.root.point == nil)
+	equals(t, true, len(o.root.entries) == 0)
 }
 
 func TestRetrievesElementsIn(t *testing.T) {
-	o := CreateOctree(Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
 
 	o.Add(11, Vector3f{0, 0, 0})
 	// contains point
@@ -208,7 +210,7 @@ func TestRetrievesElementsIn(t *testing.T) {
 	equals(t, true, o.root.hasChildren)
 	equals(t, false, o.root.children == nil)
 	equals(t, 8, len(o.root.children))
-	equals(t, true, o.root.point == nil)
+	equals(t, true, len(o.root.entries) == 0)
 
 	// contains point
 	equals(t, 2, len(o.ElementsIn(Box{Vector3f{-1, -1, -1}, Vector3f{0.1, 0.1, 0.1}})))
@@ -218,7 +220,7 @@ func TestRetrievesElementsIn(t *testing.T) {
 	equals(t, 3, len(o.ElementsIn(Box{Vector3f{-1, -1, -1}, Vector3f{2, 2, 2}})))
 
 	// fresh octree
-	o = CreateOctree(Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+	o = CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
 	equals(t, false, o.root.hasChildren)
 
 	o.Add(11, Vector3f{0.4, 0.4, 0.4})
@@ -236,6 +238,10 @@ func TestRetrievesElementsIn(t *testing.T) {
 	equals(t, 2, len(o.ElementsIn(Box{Vector3f{0.68, 0.69, 0.7}, Vector3f{0.68, 0.69, 0.7}})))
 	equals(t, 1, len(o.ElementsIn(Box{Vector3f{0.35, 0.35, 0.35}, Vector3f{0.45, 0.45, 0.45}})))
 
+	// nil where there are none, whether the tree is subdivided or not
+	equals(t, []int(nil), o.ElementsIn(Box{Vector3f{0.9, 0.9, 0.9}, Vector3f{1, 1, 1}}))
+	equals(t, []int(nil), CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1}).ElementsIn(Box{Vector3f{0, 0, 0}, Vector3f{1, 1, 1}}))
+
 	o.Add(14, Vector3f{0.1, 0.9, 0.1})
 
 	// values
@@ -245,7 +251,7 @@ func TestRetrievesElementsIn(t *testing.T) {
 }
 
 func TestRetrievesElementsAt(t *testing.T) {
-	o := CreateOctree(Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
 
 	o.Add(11, Vector3f{0.1, 0.1, 0.1})
 	// finds element at point
@@ -280,7 +286,7 @@ func TestRetrievesElementsAt(t *testing.T) {
 }
 
 func TestRemovesElements(t *testing.T) {
-	o := CreateOctree(Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
 
 	// removes element
 	o.Add(11, Vector3f{0.1, 0.1, 0.1})
@@ -330,7 +336,7 @@ func TestRemovesElements(t *testing.T) {
 }
 
 func TestRemovesElementsUsing(t *testing.T) {
-	o := CreateOctree(Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
 
 	// removes element using node ref
 	node11 := o.Add(11, Vector3f{0.1, 0.1, 0.1})
@@ -368,7 +374,7 @@ func TestRemovesElementsUsing(t *testing.T) {
 }
 
 func TestClearTree(t *testing.T) {
-	o := CreateOctree(Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
 	equals(t, 0, len(o.ElementsAt(Vector3f{0.1, 0.1, 0.1})))
 	o.Add(11, Vector3f{0.1, 0.1, 0.1})
 	equals(t, 1, len(o.ElementsAt(Vector3f{0.1, 0.1, 0.1})))
@@ -376,3 +382,312 @@ func TestClearTree(t *testing.T) {
 	o.Clear()
 	equals(t, 0, len(o.ElementsAt(Vector3f{0.1, 0.1, 0.1})))
 }
+
+func TestRemovesNonComparableElements(t *testing.T) {
+	o := CreateOctree[[]int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+
+	o.Add([]int{1, 2}, Vector3f{0.1, 0.1, 0.1})
+	o.Add([]int{3}, Vector3f{0.7, 0.7, 0.7})
+	equals(t, [][]int{{1, 2}}, o.ElementsAt(Vector3f{0.1, 0.1, 0.1}))
+	equals(t, false, o.Remove([]int{1}))
+	equals(t, true, o.Remove([]int{1, 2}))
+	equals(t, 0, len(o.ElementsAt(Vector3f{0.1, 0.1, 0.1})))
+	equals(t, 1, len(o.ElementsAt(Vector3f{0.7, 0.7, 0.7})))
+
+	// an interface type can hold values that aren't comparable
+	a := CreateOctree[interface{}](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+	a.Add([]int{1}, Vector3f{0.1, 0.1, 0.1})
+	a.Add(map[string]int{"a": 1}, Vector3f{0.1, 0.1, 0.1})
+	a.Add(1, Vector3f{0.1, 0.1, 0.1})
+	a.Add(nil, Vector3f{0.1, 0.1, 0.1})
+	equals(t, false, a.Remove([]int{2}))
+	equals(t, true, a.Remove([]int{1}))
+	equals(t, true, a.Remove(map[string]int{"a": 1}))
+	equals(t, true, a.Remove(nil))
+	equals(t, []interface{}{1}, a.ElementsAt(Vector3f{0.1, 0.1, 0.1}))
+
+	// as can the fields of structs, and elements of arrays
+	type holder struct {
+		ID    int
+		Value interface{}
+	}
+	s := CreateOctree[holder](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+	s.Add(holder{1, []int{1}}, Vector3f{0.1, 0.1, 0.1})
+	s.Add(holder{2, "a"}, Vector3f{0.1, 0.1, 0.1})
+	equals(t, false, s.Remove(holder{1, []int{2}}))
+	equals(t, true, s.Remove(holder{1, []int{1}}))
+	equals(t, true, s.Remove(holder{2, "a"}))
+
+	r := CreateOctree[[1]interface{}](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+	r.Add([1]interface{}{map[int]int{}}, Vector3f{0.1, 0.1, 0.1})
+	equals(t, true, r.Remove([1]interface{}{map[int]int{}}))
+	equals(t, 0, r.root.count)
+}
+
+func TestRemovesElementsWithEquals(t *testing.T) {
+	type item struct {
+		id   int
+		tags map[string]bool
+	}
+	o := CreateOctree[item](Vector3f{0, 0, 0}, Vector3f{1, 1, 1}, WithEquals(func(a, b item) bool {
+		return a.id == b.id
+	}))
+
+	node := o.Add(item{id: 1, tags: map[string]bool{"a": true}}, Vector3f{0.1, 0.1, 0.1})
+	o.Add(item{id: 2}, Vector3f{0.1, 0.1, 0.1})
+	equals(t, false, o.RemoveUsing(item{id: 3}, node))
+	equals(t, true, o.RemoveUsing(item{id: 1}, node))
+	equals(t, []item{{id: 2}}, o.ElementsAt(Vector3f{0.1, 0.1, 0.1}))
+}
+
+func TestBoxDistanceToPoint(t *testing.T) {
+	b := Box{
+		min: Vector3f{0, 0, 0},
+		max: Vector3f{1, 1, 1},
+	}
+
+	equals(t, 0.0, b.DistanceToPoint(&Vector3f{0.5, 0.5, 0.5}))
+	equals(t, 0.0, b.DistanceToPoint(&Vector3f{1, 1, 1}))
+	equals(t, 1.0, b.DistanceToPoint(&Vector3f{2, 0.5, 0.5}))
+	equals(t, 1.0, b.DistanceToPoint(&Vector3f{0.5, -1, 0.5}))
+	equals(t, 5.0, b.DistanceToPoint(&Vector3f{0.5, 4, 5}))
+}
+
+func TestBoxMaxDistanceToPoint(t *testing.T) {
+	b := Box{
+		min: Vector3f{0, 0, 0},
+		max: Vector3f{2, 2, 1},
+	}
+
+	equals(t, 3.0, b.MaxDistanceToPoint(&Vector3f{0, 0, 0}))
+	equals(t, 1.5, b.MaxDistanceToPoint(&Vector3f{1, 1, 0.5}))
+	equals(t, 3.0, b.MaxDistanceToPoint(&Vector3f{2, 0, 1}))
+}
+
+func TestBoxIntersectsRay(t *testing.T) {
+	b := Box{
+		min: Vector3f{0, 0, 0},
+		max: Vector3f{1, 1, 1},
+	}
+
+	near, far, ok := b.IntersectsRay(&Vector3f{-1, 0.5, 0.5}, &Vector3f{1, 0, 0})
+	equals(t, true, ok)
+	equals(t, 1.0, near)
+	equals(t, 2.0, far)
+
+	// from inside
+	near, far, ok = b.IntersectsRay(&Vector3f{0.5, 0.5, 0.5}, &Vector3f{0, 0, -2})
+	equals(t, true, ok)
+	equals(t, -0.25, near)
+	equals(t, 0.25, far)
+
+	// behind the origin
+	near, far, ok = b.IntersectsRay(&Vector3f{2, 0.5, 0.5}, &Vector3f{1, 0, 0})
+	equals(t, true, ok)
+	equals(t, -2.0, near)
+	equals(t, -1.0, far)
+
+	// diagonal through a corner
+	near, _, ok = b.IntersectsRay(&Vector3f{2, 2, 2}, &Vector3f{-1, -1, -1})
+	equals(t, true, ok)
+	equals(t, 1.0, near)
+
+	// misses
+	_, _, ok = b.IntersectsRay(&Vector3f{-1, 1.5, 0.5}, &Vector3f{1, 0, 0})
+	equals(t, false, ok)
+	_, _, ok = b.IntersectsRay(&Vector3f{-1, -1, 0.5}, &Vector3f{1, 3, 0})
+	equals(t, false, ok)
+	_, _, ok = b.IntersectsRay(&Vector3f{-1, 0.5, 0.5}, &Vector3f{0, 1, 0})
+	equals(t, false, ok)
+}
+
+func TestLeafCapacity(t *testing.T) {
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1}, WithCapacity(3))
+
+	o.Add(1, Vector3f{0.1, 0.1, 0.1})
+	o.Add(2, Vector3f{0.2, 0.2, 0.2})
+	o.Add(3, Vector3f{0.2, 0.2, 0.2})
+	root := o.Add(4, Vector3f{0.9, 0.9, 0.9})
+	equals(t, o.root, root)
+	equals(t, false, o.root.hasChildren)
+	equals(t, 3, len(o.root.entries))
+
+	// fourth distinct point subdivides
+	leaf := o.Add(5, Vector3f{0.3, 0.3, 0.3})
+	equals(t, true, o.root.hasChildren)
+	equals(t, 0, len(o.root.entries))
+	equals(t, o.root.children[0], leaf)
+	equals(t, 3, len(leaf.entries))
+
+	equals(t, []int{2, 3}, o.ElementsAt(Vector3f{0.2, 0.2, 0.2}))
+	equals(t, []int{4}, o.ElementsAt(Vector3f{0.9, 0.9, 0.9}))
+	equals(t, []int{1, 2, 3, 5}, o.ElementsIn(Box{Vector3f{0, 0, 0}, Vector3f{0.5, 0.5, 0.5}}))
+	equals(t, true, o.RemoveUsing(2, leaf))
+	equals(t, []int{3}, o.ElementsAt(Vector3f{0.2, 0.2, 0.2}))
+}
+
+func TestMaxDepth(t *testing.T) {
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1}, WithMaxDepth(3))
+
+	// nearly-coincident points would otherwise subdivide ~50 times
+	o.Add(1, Vector3f{0.3, 0.3, 0.3})
+	o.Add(2, Vector3f{0.3 + 1e-15, 0.3, 0.3})
+	o.Add(3, Vector3f{0.3, 0.3 + 1e-15, 0.3})
+	equals(t, 3, maxDepth(o.root))
+
+	equals(t, []int{1}, o.ElementsAt(Vector3f{0.3, 0.3, 0.3}))
+	equals(t, []int{2}, o.ElementsAt(Vector3f{0.3 + 1e-15, 0.3, 0.3}))
+	equals(t, []int{3}, o.ElementsAt(Vector3f{0.3, 0.3 + 1e-15, 0.3}))
+	equals(t, 3, len(o.ElementsIn(Box{Vector3f{0, 0, 0}, Vector3f{1, 1, 1}})))
+
+	// unlimited by default
+	o = CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+	o.Add(1, Vector3f{0.3, 0.3, 0.3})
+	o.Add(2, Vector3f{0.3 + 1e-9, 0.3, 0.3})
+	equals(t, true, maxDepth(o.root) > 20)
+}
+
+func maxDepth[T any](n *Node[T]) int {
+	depth := 0
+	for _, child := range n.children {
+		if d := maxDepth(child) + 1; d > depth {
+			depth = d
+		}
+	}
+	return depth
+}
+
+func TestRemoveCollapsesNodes(t *testing.T) {
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+
+	// empty leaf forgets its point
+	o.Add(1, Vector3f{0.1, 0.1, 0.1})
+	equals(t, true, o.Remove(1))
+	equals(t, 0, len(o.root.entries))
+	equals(t, 0, o.root.count)
+
+	o.Add(3, Vector3f{0.9, 0.9, 0.9})
+	o.Add(1, Vector3f{0.1, 0.1, 0.1})
+	node2 := o.Add(2, Vector3f{0.1, 0.1, 0.2})
+	equals(t, true, o.root.hasChildren)
+	equals(t, 3, o.root.count)
+	equals(t, true, maxDepth(o.root) > 2)
+
+	// only one point left in the subtree of root.children[0]
+	equals(t, true, o.Remove(1))
+	equals(t, 2, o.root.count)
+	equals(t, false, o.root.children[0].hasChildren)
+	equals(t, []Vector3f{{0.1, 0.1, 0.2}}, entryPoints(o.root.children[0]))
+	equals(t, true, node2.detached)
+
+	// only one point left in the whole tree
+	equals(t, true, o.Remove(3))
+	equals(t, false, o.root.hasChildren)
+	equals(t, true, o.root.children == nil)
+	equals(t, []Vector3f{{0.1, 0.1, 0.2}}, entryPoints(o.root))
+
+	// stale node forwards to the node it was collapsed into
+	equals(t, true, o.RemoveUsing(2, node2))
+	equals(t, false, o.RemoveUsing(2, node2))
+	equals(t, 0, o.root.count)
+}
+
+func TestRemoveCollapsesToCapacity(t *testing.T) {
+	r := rand.New(rand.NewSource(6))
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1}, WithCapacity(4))
+	nodes := make([]*Node[int], 100)
+	for i := range nodes {
+		nodes[i] = o.Add(i, Vector3f{r.Float64(), r.Float64(), r.Float64()})
+	}
+	equals(t, 100, o.root.count)
+
+	for i := 0; i < 95; i++ {
+		equals(t, true, o.RemoveUsing(i, nodes[i]))
+		equals(t, 99-i, o.root.count)
+		checkCounts(t, o.root)
+	}
+	equals(t, true, o.root.hasChildren)
+
+	equals(t, true, o.RemoveUsing(95, nodes[95]))
+	equals(t, false, o.root.hasChildren)
+	equals(t, 4, len(o.root.entries))
+	equals(t, 4, len(o.ElementsIn(Box{Vector3f{0, 0, 0}, Vector3f{1, 1, 1}})))
+}
+
+func entryPoints[T any](n *Node[T]) []Vector3f {
+	points := []Vector3f{}
+	for _, e := range n.entries {
+		points = append(points, e.point)
+	}
+	return points
+}
+
+func checkCounts[T any](tb testing.TB, n *Node[T]) int {
+	count := n.load()
+	for _, child := range n.children {
+		if child.gen == n.gen {
+			// nodes shared with a snapshot keep the parent they had there
+			equals(tb, n, child.parent)
+		}
+		count += checkCounts(tb, child)
+	}
+	equals(tb, count, n.count)
+	return count
+}
+
+func TestSubdividesNearlyCoincidentPoints(t *testing.T) {
+	o := CreateOctree[int](Vector3f{0.5, 0.5, 0.5}, Vector3f{1, 1, 1})
+
+	// no depth limit, and the points are as close as possible
+	next := math.Nextafter(0.7, 1)
+	o.Add(1, Vector3f{0.7, 0.7, 0.7})
+	o.Add(2, Vector3f{next, next, next})
+	equals(t, []int{1}, o.ElementsAt(Vector3f{0.7, 0.7, 0.7}))
+	equals(t, []int{2}, o.ElementsAt(Vector3f{next, next, next}))
+	equals(t, true, maxDepth(o.root) > 40)
+
+	// boxes that are too small to split
+	b := Box{Vector3f{0.7, 0, 0}, Vector3f{next, 0, 0}}
+	equals(t, false, b.canSplit())
+	b = Box{Vector3f{0.7, 0, 0}, Vector3f{1, 0, 0}}
+	equals(t, true, b.canSplit())
+}
+
+func TestInspectsNodes(t *testing.T) {
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+	o.Add(3, Vector3f{0.9, 0.9, 0.9})
+	node1 := o.Add(1, Vector3f{0.1, 0.1, 0.1})
+	o.Add(2, Vector3f{0.1, 0.1, 0.1})
+	o.AddBox(4, Box{Vector3f{0.4, 0.4, 0.4}, Vector3f{0.6, 0.6, 0.6}})
+
+	root := node1.Parent()
+	equals(t, o.root, root)
+	equals(t, (*Node[int])(nil), root.Parent())
+	equals(t, 0, root.Depth())
+	equals(t, false, root.IsLeaf())
+	equals(t, []int{4}, root.Elements())
+	equals(t, NewBox(Vector3f{1, 1, 1}, Vector3f{0, 0, 0}), root.Bounds())
+
+	_, ok := root.Point()
+	equals(t, false, ok)
+
+	children := root.Children()
+	equals(t, 8, len(children))
+	equals(t, node1, children[0])
+	node3 := children[7]
+	children[0] = nil
+	equals(t, node1, root.Children()[0])
+
+	equals(t, 1, node1.Depth())
+	equals(t, true, node1.IsLeaf())
+	equals(t, []*Node[int](nil), node1.Children())
+	equals(t, []int{1, 2}, node1.Elements())
+	equals(t, []Vector3f{{0.1, 0.1, 0.1}}, node1.Points())
+	point, ok := node1.Point()
+	equals(t, true, ok)
+	equals(t, Vector3f{0.1, 0.1, 0.1}, point)
+
+	equals(t, Vector3f{0.5, 0.5, 0.5}, node3.Bounds().Min())
+	equals(t, Vector3f{1, 1, 1}, node3.Bounds().Max())
+	equals(t, Vector3f{0.5, 0.5, 0.5}, node3.Bounds().Size())
+}
@@ -0,0 +1,90 @@
+package octree
+
+import (
+	"runtime"
+	"sync"
+)
+
+// parallelThreshold is the number of distinct points and volumes
+// a subtree must hold before a query splits it between goroutines.
+const parallelThreshold = 1 << 13
+
+// WithParallelism Sets the number of goroutines a single ElementsIn or
+// ElementsWithin query may use, which only pays off when queries cover large
+// parts of big trees. Subtrees holding more than a few thousand points are
+// then searched concurrently. Defaults to 1, searching on the calling
+// goroutine only; values less than 1 use runtime.GOMAXPROCS.
+func WithParallelism(n int) Option {
+	return func(opts *options) {
+		if n < 1 {
+			n = runtime.GOMAXPROCS(0)
+		}
+		opts.parallelism = n
+	}
+}
+
+// fanOut Bounds the goroutines a single query has started.
+type fanOut struct {
+	tokens chan struct{}
+}
+
+func (o *Octree[T]) fanOut() *fanOut {
+	// nil when queries aren't split at all
+	if o.parallelism <= 1 {
+		return nil
+	}
+
+	return &fanOut{tokens: make(chan struct{}, o.parallelism-1)}
+}
+
+func (f *fanOut) splits(count int) bool {
+	return f != nil && count > parallelThreshold
+}
+
+func fanOutChildren[T any](f *fanOut, n *Node[T], elements []T, visit func(child *Node[T], elements []T) []T) []T {
+	// visit the children of n, appending what they find to elements
+	// in the order of the children. The last ones are visited on other
+	// goroutines while tokens are available, and the rest directly
+	// on this one, so only what the others find is copied.
+
+	inline := len(n.children)
+	parts := make([][]T, len(n.children))
+	var wg sync.WaitGroup
+
+	for inline > 1 {
+		select {
+		case f.tokens <- struct{}{}:
+			inline--
+			i, child := inline, n.children[inline]
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				parts[i] = visit(child, nil)
+				<-f.tokens
+			}()
+			continue
+		default:
+		}
+		break
+	}
+
+	for _, child := range n.children[:inline] {
+		elements = visit(child, elements)
+	}
+
+	wg.Wait()
+
+	size := len(elements)
+	for _, part := range parts[inline:] {
+		size += len(part)
+	}
+
+	if cap(elements) < size {
+		elements = append(make([]T, 0, size), elements...)
+	}
+	for _, part := range parts[inline:] {
+		elements = append(elements, part...)
+	}
+
+	return elements
+}
@@ -0,0 +1,74 @@
+package octree
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestParallelQueriesMatchSequential(t *testing.T) {
+	r := rand.New(rand.NewSource(14))
+	sequential := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1}, WithCapacity(8))
+	parallel := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1}, WithCapacity(8), WithParallelism(4))
+
+	for i := 0; i < 3*parallelThreshold; i++ {
+		point := Vector3f{r.Float64(), r.Float64(), r.Float64()}
+		sequential.Add(i, point)
+		parallel.Add(i, point)
+		if i%100 == 0 {
+			size := Vector3f{0.01, 0.01, 0.01}
+			min := point.Scale(0.99)
+			box := Box{min, min.Plus(&size)}
+			sequential.AddBox(-i, box)
+			parallel.AddBox(-i, box)
+		}
+	}
+
+	for i := 0; i < 20; i++ {
+		a := Vector3f{r.Float64(), r.Float64(), r.Float64()}
+		b := Vector3f{r.Float64(), r.Float64(), r.Float64()}
+		box := Box{a.Min(&b), a.Max(&b)}
+		equals(t, sequential.ElementsIn(box), parallel.ElementsIn(box))
+
+		radius := r.Float64()
+		equals(t, sequential.ElementsWithin(a, radius), parallel.ElementsWithin(a, radius))
+	}
+
+	all := Box{Vector3f{0, 0, 0}, Vector3f{1, 1, 1}}
+	equals(t, 3*parallelThreshold+3*parallelThreshold/100+1, len(parallel.ElementsIn(all)))
+
+	// a snapshot keeps the setting
+	equals(t, sequential.ElementsWithin(Vector3f{0.5, 0.5, 0.5}, 2), parallel.Snapshot().ElementsWithin(Vector3f{0.5, 0.5, 0.5}, 2))
+}
+
+func BenchmarkElementsIn(b *testing.B) {
+	r := rand.New(rand.NewSource(14))
+	points := make([]Vector3f, 2_000_000)
+	elements := make([]int, len(points))
+	for i := range points {
+		points[i] = Vector3f{r.Float64(), r.Float64(), r.Float64()}
+		elements[i] = i
+	}
+
+	// a quarter of the tree
+	box := Box{Vector3f{0.1, 0.1, 0.1}, Vector3f{0.73, 0.73, 0.73}}
+
+	for _, test := range []struct {
+		name string
+		opts []Option
+	}{
+		{"sequential", []Option{WithCapacity(8)}},
+		{"parallel", []Option{WithCapacity(8), WithParallelism(4)}},
+	} {
+		o, err := BuildOctree(points, elements, test.opts...)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(test.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				o.ElementsIn(box)
+			}
+		})
+	}
+}
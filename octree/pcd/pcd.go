@@ -0,0 +1,190 @@
+// Package pcd Reads point clouds from PCD (Point Cloud Library) files into
+// octrees, and writes points back out, in ASCII or binary format.
+package pcd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/bjnsn/go-octree/octree"
+)
+
+// ErrFormat The data read isn't a PCD file, or holds points that can't be read.
+var ErrFormat = errors.New("pcd: invalid format")
+
+// Format The encoding of the data following the header of a PCD file.
+type Format int
+
+const (
+	ASCII Format = iota
+	Binary
+)
+
+// Header Describes the points of a PCD file.
+type Header struct {
+	Format Format
+	// Points is the number of points in the file. It is ignored by Write.
+	Points int
+	// PositionSize is the size in bytes of the float x, y and z fields
+	// written by Write; 4 (as PCL writes them) if zero, or 8 to keep the
+	// precision of a float64. NewReader sets it to 8 if any of the fields
+	// read has 8 bytes, and to 4 otherwise.
+	PositionSize int
+	// Color and Intensity are whether the points have rgb and intensity fields.
+	Color     bool
+	Intensity bool
+}
+
+// Point The position of a point and its color and intensity, where its file
+// has them. Queries on a tree of points return them as they were read, ready
+// to be passed to Write.
+type Point struct {
+	Position octree.Vector3f
+	// Color holds the red, green and blue components of the color.
+	Color     [3]uint8
+	Intensity float64
+}
+
+// field A field of the points of a PCD file.
+type field struct {
+	name string
+	// typ is one of I, U and F; a signed or unsigned integer, or a float.
+	typ   byte
+	size  int
+	count int
+}
+
+func formatError(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %v", ErrFormat, fmt.Sprintf(format, args...))
+}
+
+func readHeader(r *bufio.Reader) (*Header, []field, error) {
+	// read the header of a PCD file, up to and including
+	// its DATA line, returning the fields it describes.
+
+	values := map[string][]string{}
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return nil, nil, formatError("header has no DATA line")
+		}
+
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		key := strings.ToUpper(fields[0])
+		switch key {
+		case "VERSION", "FIELDS", "SIZE", "TYPE", "COUNT", "WIDTH", "HEIGHT", "VIEWPOINT", "POINTS", "DATA":
+			values[key] = fields[1:]
+		default:
+			return nil, nil, formatError("unknown header line %q", strings.TrimSpace(line))
+		}
+
+		if key == "DATA" {
+			break
+		}
+	}
+
+	names, sizes, types, counts := values["FIELDS"], values["SIZE"], values["TYPE"], values["COUNT"]
+	if counts == nil {
+		counts = make([]string, len(names))
+		for i := range counts {
+			counts[i] = "1"
+		}
+	}
+	if len(names) == 0 || len(sizes) != len(names) || len(types) != len(names) || len(counts) != len(names) {
+		return nil, nil, formatError("FIELDS, SIZE, TYPE and COUNT don't agree")
+	}
+
+	fields := make([]field, len(names))
+	for i := range fields {
+		f := field{name: names[i]}
+		var err error
+		if f.size, err = strconv.Atoi(sizes[i]); err != nil {
+			return nil, nil, formatError("invalid size %q of field %q", sizes[i], f.name)
+		}
+		if f.count, err = strconv.Atoi(counts[i]); err != nil || f.count < 1 {
+			return nil, nil, formatError("invalid count %q of field %q", counts[i], f.name)
+		}
+		if len(types[i]) == 1 {
+			f.typ = types[i][0]
+		}
+
+		switch {
+		case f.typ == 'F' && (f.size == 4 || f.size == 8):
+		case (f.typ == 'I' || f.typ == 'U') && (f.size == 1 || f.size == 2 || f.size == 4 || f.size == 8):
+		default:
+			return nil, nil, formatError("invalid type %q of size %d of field %q", types[i], f.size, f.name)
+		}
+		fields[i] = f
+	}
+
+	header := &Header{}
+	switch data := values["DATA"]; {
+	case len(data) == 1 && data[0] == "ascii":
+		header.Format = ASCII
+	case len(data) == 1 && data[0] == "binary":
+		header.Format = Binary
+	default:
+		return nil, nil, formatError("unsupported data %q", strings.Join(data, " "))
+	}
+
+	if points := values["POINTS"]; points != nil {
+		n, err := strconv.Atoi(strings.Join(points, " "))
+		if err != nil || n < 0 {
+			return nil, nil, formatError("invalid number of points %q", strings.Join(points, " "))
+		}
+		header.Points = n
+	} else {
+		width, err1 := strconv.Atoi(strings.Join(values["WIDTH"], " "))
+		height, err2 := strconv.Atoi(strings.Join(values["HEIGHT"], " "))
+		if err1 != nil || err2 != nil || width < 0 || height < 0 {
+			return nil, nil, formatError("header has neither POINTS nor WIDTH and HEIGHT")
+		}
+		header.Points = width * height
+	}
+
+	return header, fields, nil
+}
+
+func writeHeader(w io.Writer, header *Header, points int) error {
+	positionSize := header.PositionSize
+	if positionSize == 0 {
+		positionSize = 4
+	}
+	if positionSize != 4 && positionSize != 8 {
+		return fmt.Errorf("pcd: invalid position size %d", positionSize)
+	}
+
+	var data string
+	switch header.Format {
+	case ASCII:
+		data = "ascii"
+	case Binary:
+		data = "binary"
+	default:
+		return fmt.Errorf("pcd: unknown format %d", header.Format)
+	}
+
+	names, sizes, types := "x y z", strings.Repeat(fmt.Sprintf(" %d", positionSize), 3), " F F F"
+	if header.Color {
+		names, sizes, types = names+" rgb", sizes+" 4", types+" F"
+	}
+	if header.Intensity {
+		names, sizes, types = names+" intensity", sizes+" 4", types+" F"
+	}
+
+	_, err := fmt.Fprintf(w, "# .PCD v0.7 - Point Cloud Data file format\nVERSION 0.7\nFIELDS %v\nSIZE%v\nTYPE%v\nCOUNT%v\n"+
+		"WIDTH %d\nHEIGHT 1\nVIEWPOINT 0 0 0 1 0 0 0\nPOINTS %d\nDATA %v\n",
+		names, sizes, types, strings.Repeat(" 1", len(strings.Fields(names))), points, points, data)
+	return err
+}
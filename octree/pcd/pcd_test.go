@@ -0,0 +1,183 @@
+package pcd
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/bjnsn/go-octree/octree"
+	"github.com/bjnsn/go-octree/octree/internal/testutil"
+)
+
+var equals = testutil.Equals
+
+// an organized cloud, as PCL writes one, with a missing point
+const organized = `# .PCD v0.7 - Point Cloud Data file format
+VERSION 0.7
+FIELDS x y z rgb normal_x label intensity
+SIZE 4 4 8 4 4 2 4
+TYPE F F F F F I F
+COUNT 1 1 1 1 3 1 1
+WIDTH 2
+HEIGHT 2
+VIEWPOINT 0 0 0 1 0 0 0
+DATA ascii
+0 0 0 4.808e+06 0 0 1 -3 0.5
+1 0 0 2.3418052e-38 0 0 1 2 1
+nan nan nan 0 0 0 0 0 0
+
+0.25 1 2.5 0 0 0 1 7 0.75
+`
+
+func TestReadsASCII(t *testing.T) {
+	reader, err := NewReader(strings.NewReader(organized))
+	equals(t, nil, err)
+	equals(t, &Header{Format: ASCII, Points: 4, PositionSize: 8, Color: true, Intensity: true}, reader.Header())
+
+	point, err := reader.Read()
+	equals(t, nil, err)
+	// 4.808e+06 is the float with the bits 0x4a92ba80
+	equals(t, Point{octree.Vector3f{0, 0, 0}, [3]uint8{0x92, 0xba, 0x80}, 0.5}, point)
+
+	point, err = reader.Read()
+	equals(t, nil, err)
+	equals(t, [3]uint8{0xff, 0x00, 0x00}, point.Color)
+
+	point, err = reader.Read()
+	equals(t, nil, err)
+	equals(t, true, math.IsNaN(point.Position[0]))
+
+	_, err = reader.Read()
+	equals(t, nil, err)
+	_, err = reader.Read()
+	equals(t, io.EOF, err)
+
+	// into a tree bounded by the box around the points, without the missing one
+	o, _, err := Load(strings.NewReader(organized))
+	equals(t, nil, err)
+	equals(t, 3, o.Stats().Elements)
+	equals(t, octree.Vector3f{1, 1, 2.5}, o.Root().Bounds().Max())
+	equals(t, nil, o.Validate())
+}
+
+func TestReadsIntegerPositions(t *testing.T) {
+	const data = "FIELDS x y z\nSIZE 2 2 2\nTYPE I I I\nWIDTH 2\nHEIGHT 1\nDATA binary\n" +
+		"\x01\x00\xff\xff\x02\x00\x00\x80\x00\x00\xff\x7f"
+
+	reader, err := NewReader(strings.NewReader(data))
+	equals(t, nil, err)
+	equals(t, &Header{Format: Binary, Points: 2, PositionSize: 4}, reader.Header())
+
+	var points []Point
+	for {
+		point, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		equals(t, nil, err)
+		points = append(points, point)
+	}
+	equals(t, []Point{{Position: octree.Vector3f{1, -1, 2}}, {Position: octree.Vector3f{-32768, 0, 32767}}}, points)
+
+	// and written back out as floats with the header of the file
+	var buf bytes.Buffer
+	equals(t, nil, Write(&buf, reader.Header(), points))
+	written, _, err := Load(&buf)
+	equals(t, nil, err)
+	equals(t, []Point{points[1]}, written.ElementsAt(octree.Vector3f{-32768, 0, 32767}))
+}
+
+func TestRoundTrips(t *testing.T) {
+	points := []Point{
+		{octree.Vector3f{0.1, -2, 3e10}, [3]uint8{1, 2, 3}, 0.5},
+		{octree.Vector3f{1.0 / 3, 0, 0}, [3]uint8{255, 0, 128}, -1.5},
+	}
+
+	for _, header := range []*Header{
+		{Format: ASCII, Points: 2, PositionSize: 8, Color: true, Intensity: true},
+		{Format: Binary, Points: 2, PositionSize: 8, Color: true, Intensity: true},
+		{Format: Binary, Points: 2, PositionSize: 4, Intensity: true},
+		{Format: ASCII, Points: 2, PositionSize: 4},
+	} {
+		var buf bytes.Buffer
+		equals(t, nil, Write(&buf, header, points))
+
+		reader, err := NewReader(&buf)
+		equals(t, nil, err)
+		equals(t, header, reader.Header())
+		for i := range points {
+			point, err := reader.Read()
+			equals(t, nil, err)
+
+			exp := points[i]
+			if header.PositionSize == 4 {
+				for j := range exp.Position {
+					exp.Position[j] = float64(float32(exp.Position[j]))
+				}
+			}
+			if !header.Color {
+				exp.Color = [3]uint8{}
+			}
+			if !header.Intensity {
+				exp.Intensity = 0
+			}
+			equals(t, exp, point)
+		}
+		_, err = reader.Read()
+		equals(t, io.EOF, err)
+	}
+}
+
+func TestWritesQueryResults(t *testing.T) {
+	o := octree.CreateOctree[Point](octree.Vector3f{0, 0, 0}, octree.Vector3f{1, 1, 3})
+	header, err := ReadInto(strings.NewReader(organized), o)
+	equals(t, nil, err)
+
+	header.Format = Binary
+	found := o.ElementsIn(octree.NewBox(octree.Vector3f{0, 0, 0}, octree.Vector3f{1, 0.5, 0.5}))
+	var buf bytes.Buffer
+	equals(t, nil, Write(&buf, header, found))
+
+	written, _, err := Load(&buf)
+	equals(t, nil, err)
+	equals(t, o.ElementsAt(octree.Vector3f{1, 0, 0}), written.ElementsAt(octree.Vector3f{1, 0, 0}))
+	equals(t, 2, written.Stats().Elements)
+}
+
+func TestReportsErrors(t *testing.T) {
+	const fields = "FIELDS x y z\nSIZE 4 4 4\nTYPE F F F\nWIDTH 2\nHEIGHT 1\n"
+
+	for _, data := range []string{
+		"",
+		"ply\n",
+		fields,
+		fields + "DATA binary_compressed\n",
+		"FIELDS x y\nSIZE 4 4\nTYPE F F\nWIDTH 1\nHEIGHT 1\nDATA ascii\n",
+		"FIELDS x y z\nSIZE 4 4 3\nTYPE F F F\nPOINTS 1\nDATA ascii\n",
+		"FIELDS x y z\nSIZE 4 4 4\nTYPE F F\nPOINTS 1\nDATA ascii\n",
+		"FIELDS x y z\nSIZE 4 4 4\nTYPE F F F\nDATA ascii\n",
+	} {
+		_, err := NewReader(strings.NewReader(data))
+		equals(t, true, errors.Is(err, ErrFormat))
+	}
+
+	for _, data := range []string{
+		"DATA ascii\n0 0 0\n0 0\n",
+		"DATA ascii\n0 0 0\n0 0 0 0\n",
+		"DATA ascii\n0 0 0\n0 0 a\n",
+		"DATA ascii\n0 0 0\n",
+		"DATA binary\n\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00",
+	} {
+		_, _, err := Load(strings.NewReader(fields + data))
+		equals(t, true, errors.Is(err, ErrFormat))
+	}
+
+	o := octree.CreateOctree[Point](octree.Vector3f{0, 0, 0}, octree.Vector3f{0.5, 0.5, 0.5})
+	_, err := ReadInto(strings.NewReader(organized), o)
+	equals(t, true, errors.Is(err, octree.ErrOutOfBounds))
+
+	equals(t, false, Write(io.Discard, &Header{PositionSize: 2}, nil) == nil)
+}
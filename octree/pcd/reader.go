@@ -0,0 +1,244 @@
+package pcd
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/bjnsn/go-octree/octree"
+	"github.com/bjnsn/go-octree/octree/internal/cloud"
+)
+
+// Reader Reads the points of a PCD file one at a time, decoding the fields
+// a Point has from each record and skipping the rest, such as normals.
+type Reader struct {
+	r      *bufio.Reader
+	header *Header
+	fields []field
+	// x, y, z, rgb and intensity are the index of
+	// those fields, or -1 for those that are missing.
+	x, y, z, rgb, intensity int
+	record                  []byte
+	read                    int
+}
+
+// NewReader Reads the header of the PCD file read from r, so that Read
+// returns its first point. Returns an error wrapping ErrFormat if r doesn't
+// hold a PCD file with x, y and z fields. Files with compressed binary data
+// are not supported.
+func NewReader(r io.Reader) (*Reader, error) {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+
+	header, fields, err := readHeader(br)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := &Reader{r: br, header: header, fields: fields, x: -1, y: -1, z: -1, rgb: -1, intensity: -1}
+	size := 0
+	for i, f := range fields {
+		switch f.name {
+		case "x":
+			reader.x = i
+		case "y":
+			reader.y = i
+		case "z":
+			reader.z = i
+		case "rgb", "rgba":
+			reader.rgb = i
+			header.Color = true
+		case "intensity":
+			reader.intensity = i
+			header.Intensity = true
+		}
+		size += f.size * f.count
+	}
+
+	if reader.x < 0 || reader.y < 0 || reader.z < 0 {
+		return nil, formatError("points have no x, y and z fields")
+	}
+	// the size positions are written with; integers are written as floats
+	header.PositionSize = 4
+	if fields[reader.x].size == 8 || fields[reader.y].size == 8 || fields[reader.z].size == 8 {
+		header.PositionSize = 8
+	}
+
+	if header.Format == Binary {
+		reader.record = make([]byte, size)
+	}
+
+	return reader, nil
+}
+
+// Header Returns the header of the file, describing its points.
+func (r *Reader) Header() *Header {
+	return r.header
+}
+
+// Read Reads the next point, returning io.EOF after the last one, or an error
+// wrapping ErrFormat if the point can't be read. The position of points
+// missing from an organized cloud is NaN, as PCL writes them.
+func (r *Reader) Read() (Point, error) {
+	if r.read == r.header.Points {
+		return Point{}, io.EOF
+	}
+
+	// the bits of the first value of each field, and its type and size
+	values := make([]uint64, len(r.fields))
+
+	if r.header.Format == Binary {
+		if _, err := io.ReadFull(r.r, r.record); err != nil {
+			return Point{}, formatError("file ends early (point %d)", r.read)
+		}
+
+		offset := 0
+		for i, f := range r.fields {
+			b := r.record[offset:]
+			switch f.size {
+			case 1:
+				values[i] = uint64(b[0])
+			case 2:
+				values[i] = uint64(binary.LittleEndian.Uint16(b))
+			case 4:
+				values[i] = uint64(binary.LittleEndian.Uint32(b))
+			case 8:
+				values[i] = binary.LittleEndian.Uint64(b)
+			}
+			offset += f.size * f.count
+		}
+	} else {
+		var tokens []string
+		for len(tokens) == 0 {
+			line, err := r.r.ReadString('\n')
+			if err != nil && (err != io.EOF || line == "") {
+				return Point{}, formatError("file ends early (point %d)", r.read)
+			}
+			tokens = strings.Fields(line)
+		}
+
+		for i, f := range r.fields {
+			if len(tokens) < f.count {
+				return Point{}, formatError("point %d has too few values", r.read)
+			}
+			bits, err := parseBits(&f, tokens[0])
+			if err != nil {
+				return Point{}, formatError("invalid value %q of field %q (point %d)", tokens[0], f.name, r.read)
+			}
+			values[i] = bits
+			tokens = tokens[f.count:]
+		}
+		if len(tokens) != 0 {
+			return Point{}, formatError("point %d has too many values", r.read)
+		}
+	}
+
+	var point Point
+	for i, axis := range []int{r.x, r.y, r.z} {
+		point.Position[i] = number(&r.fields[axis], values[axis])
+	}
+	if r.rgb >= 0 {
+		// packed as 0x00rrggbb (or 0xaarrggbb), whatever its type
+		rgb := uint32(values[r.rgb])
+		point.Color = [3]uint8{uint8(rgb >> 16), uint8(rgb >> 8), uint8(rgb)}
+	}
+	if r.intensity >= 0 {
+		point.Intensity = number(&r.fields[r.intensity], values[r.intensity])
+	}
+
+	r.read++
+	return point, nil
+}
+
+// ReadInto Streams the points of the PCD file read from r into the tree,
+// returning the header of the file. Points with NaN positions, which PCL
+// uses for those missing from organized clouds, are skipped. Returns an
+// error wrapping ErrFormat if the file can't be read; if a point lies outside
+// the tree (see octree.WithGrowth), the *octree.PointError from adding it is
+// returned, with the points before it already added.
+func ReadInto(r io.Reader, o *octree.Octree[Point]) (*Header, error) {
+	reader, err := NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cloud.ReadInto(o, reader.next, reader.where); err != nil {
+		return nil, err
+	}
+	return reader.Header(), nil
+}
+
+// Load Builds a tree fitted to the box around the points of the PCD file read
+// from r with octree.BuildOctree, returning it along with the header of the
+// file. Points missing from an organized cloud, with NaN positions, are left
+// out, so the tree may hold fewer than the header's Points.
+func Load(r io.Reader, opts ...octree.Option) (*octree.Octree[Point], *Header, error) {
+	reader, err := NewReader(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	o, err := cloud.Build(reader.next, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return o, reader.Header(), nil
+}
+
+func (r *Reader) next() (Point, octree.Vector3f, error) {
+	// the next point that isn't missing
+	for {
+		point, err := r.Read()
+		if err != nil || !missing(&point) {
+			return point, point.Position, err
+		}
+	}
+}
+
+func (r *Reader) where() string {
+	return fmt.Sprintf("pcd: adding point %d", r.read-1)
+}
+
+func missing(point *Point) bool {
+	return math.IsNaN(point.Position[0]) || math.IsNaN(point.Position[1]) || math.IsNaN(point.Position[2])
+}
+
+func parseBits(f *field, token string) (uint64, error) {
+	// parse a value of the field written as text,
+	// returning it as the bits it has in binary.
+	bits := f.size * 8
+	switch f.typ {
+	case 'F':
+		v, err := strconv.ParseFloat(token, bits)
+		if f.size == 4 {
+			return uint64(math.Float32bits(float32(v))), err
+		}
+		return math.Float64bits(v), err
+	case 'U':
+		return strconv.ParseUint(token, 10, bits)
+	}
+	v, err := strconv.ParseInt(token, 10, bits)
+	return uint64(v) & (math.MaxUint64 >> (64 - bits)), err
+}
+
+func number(f *field, bits uint64) float64 {
+	// the value of the field with the bits
+	switch {
+	case f.typ == 'F' && f.size == 4:
+		return float64(math.Float32frombits(uint32(bits)))
+	case f.typ == 'F':
+		return math.Float64frombits(bits)
+	case f.typ == 'U':
+		return float64(bits)
+	}
+
+	// sign extend
+	shift := 64 - f.size*8
+	return float64(int64(bits<<shift) >> shift)
+}
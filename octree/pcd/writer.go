@@ -0,0 +1,65 @@
+package pcd
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+	"strconv"
+)
+
+// Write Writes the points to w as an unorganized PCD file with the format,
+// position size and fields of the header; e.g. the header of the file they
+// were read from, or one describing just their position. Colors are packed
+// into a float rgb field, as PCL does.
+func Write(w io.Writer, header *Header, points []Point) error {
+	bw := bufio.NewWriter(w)
+	if err := writeHeader(bw, header, len(points)); err != nil {
+		return err
+	}
+
+	positionSize := header.PositionSize
+	if positionSize == 0 {
+		positionSize = 4
+	}
+
+	var buf []byte
+	for i := range points {
+		point := &points[i]
+		buf = buf[:0]
+
+		for j := 0; j < 3; j++ {
+			buf = appendFloat(buf, header.Format, j > 0, point.Position[j], positionSize)
+		}
+		if header.Color {
+			rgb := uint32(point.Color[0])<<16 | uint32(point.Color[1])<<8 | uint32(point.Color[2])
+			buf = appendFloat(buf, header.Format, true, float64(math.Float32frombits(rgb)), 4)
+		}
+		if header.Intensity {
+			buf = appendFloat(buf, header.Format, true, point.Intensity, 4)
+		}
+
+		if header.Format == ASCII {
+			buf = append(buf, '\n')
+		}
+		if _, err := bw.Write(buf); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+func appendFloat(buf []byte, format Format, separate bool, value float64, size int) []byte {
+	if format == Binary {
+		if size == 4 {
+			return binary.LittleEndian.AppendUint32(buf, math.Float32bits(float32(value)))
+		}
+		return binary.LittleEndian.AppendUint64(buf, math.Float64bits(value))
+	}
+
+	if separate {
+		buf = append(buf, ' ')
+	}
+	return strconv.AppendFloat(buf, value, 'g', -1, size*8)
+}
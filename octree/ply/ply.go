@@ -0,0 +1,246 @@
+// Package ply Reads point clouds from PLY (Polygon File Format) files into
+// octrees, and writes vertices back out, in ASCII or binary format.
+package ply
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/bjnsn/go-octree/octree"
+)
+
+// ErrFormat The data read isn't a PLY file, or holds vertices that can't be read.
+var ErrFormat = errors.New("ply: invalid format")
+
+// Format The encoding of the data following the header of a PLY file.
+type Format int
+
+const (
+	ASCII Format = iota
+	BinaryLittleEndian
+	BinaryBigEndian
+)
+
+var formatNames = []string{"ascii", "binary_little_endian", "binary_big_endian"}
+
+// Property A property of the vertices in a PLY file, other than their position.
+type Property struct {
+	Name string
+	// Type is the PLY type of the property, e.g. "uchar" or "float".
+	Type string
+}
+
+// Header Describes the vertices of a PLY file.
+type Header struct {
+	Format Format
+	// Vertices is the number of vertices in the file. It is ignored by Write.
+	Vertices int
+	// PositionType is the PLY type of the x, y and z properties;
+	// "float" if empty.
+	PositionType string
+	// Properties are those of the vertices other than x, y and z.
+	Properties []Property
+	Comments   []string
+}
+
+// Vertex The position of a vertex, and the values of its other properties
+// in the order of the Properties of the header of its file; e.g. its color
+// or intensity. Vertices found by queries on a tree of them can be written
+// back out with Write, given the header of their file.
+type Vertex struct {
+	Position octree.Vector3f
+	Values   []float64
+}
+
+// the size in bytes of each type, by name and by alias
+var typeSizes = map[string]int{
+	"char": 1, "uchar": 1, "short": 2, "ushort": 2, "int": 4, "uint": 4, "float": 4, "double": 8,
+	"int8": 1, "uint8": 1, "int16": 2, "uint16": 2, "int32": 4, "uint32": 4, "float32": 4, "float64": 8,
+}
+
+// canonical Returns the name of the type of which name is an alias, if any.
+func canonical(name string) string {
+	switch name {
+	case "int8":
+		return "char"
+	case "uint8":
+		return "uchar"
+	case "int16":
+		return "short"
+	case "uint16":
+		return "ushort"
+	case "int32":
+		return "int"
+	case "uint32":
+		return "uint"
+	case "float32":
+		return "float"
+	case "float64":
+		return "double"
+	}
+	return name
+}
+
+// element An element of a PLY file, such as its vertices or faces.
+type element struct {
+	name       string
+	count      int
+	properties []property
+}
+
+type property struct {
+	name string
+	typ  string
+	// countType is the type of the length of a list property, which
+	// holds values of typ, or empty for a property with a single value.
+	countType string
+}
+
+func formatError(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %v", ErrFormat, fmt.Sprintf(format, args...))
+}
+
+func readHeader(r *bufio.Reader) (*Header, []element, error) {
+	// read the header of a PLY file, up to and including end_header,
+	// returning the elements it describes.
+
+	readLine := func() (string, error) {
+		line, err := r.ReadString('\n')
+		if err == io.EOF && line != "" {
+			err = nil
+		} else if err == io.EOF {
+			err = formatError("header has no end_header")
+		}
+		return strings.TrimRight(line, "\r\n"), err
+	}
+
+	line, err := readLine()
+	if err != nil {
+		return nil, nil, err
+	}
+	if line != "ply" {
+		return nil, nil, formatError("file starts with %q rather than ply", line)
+	}
+
+	header := &Header{Format: -1}
+	var elements []element
+
+	for {
+		line, err := readLine()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "format":
+			if len(fields) != 3 || fields[2] != "1.0" {
+				return nil, nil, formatError("unsupported format %q", line)
+			}
+			for i, name := range formatNames {
+				if fields[1] == name {
+					header.Format = Format(i)
+				}
+			}
+			if header.Format < 0 {
+				return nil, nil, formatError("unsupported format %q", line)
+			}
+
+		case "comment":
+			header.Comments = append(header.Comments, strings.TrimSpace(strings.TrimPrefix(line, "comment")))
+
+		case "obj_info":
+
+		case "element":
+			if len(fields) != 3 {
+				return nil, nil, formatError("invalid element %q", line)
+			}
+			count, err := strconv.Atoi(fields[2])
+			if err != nil || count < 0 {
+				return nil, nil, formatError("invalid element %q", line)
+			}
+			elements = append(elements, element{name: fields[1], count: count})
+
+		case "property":
+			if len(elements) == 0 {
+				return nil, nil, formatError("property %q precedes any element", line)
+			}
+
+			var p property
+			switch {
+			case len(fields) == 3:
+				p = property{name: fields[2], typ: canonical(fields[1])}
+			case len(fields) == 5 && fields[1] == "list":
+				p = property{name: fields[4], typ: canonical(fields[3]), countType: canonical(fields[2])}
+				if typeSizes[p.countType] == 0 || p.countType == "float" || p.countType == "double" {
+					return nil, nil, formatError("invalid list length type in %q", line)
+				}
+			default:
+				return nil, nil, formatError("invalid property %q", line)
+			}
+			if typeSizes[p.typ] == 0 {
+				return nil, nil, formatError("unknown type in %q", line)
+			}
+
+			e := &elements[len(elements)-1]
+			e.properties = append(e.properties, p)
+
+		case "end_header":
+			if header.Format < 0 {
+				return nil, nil, formatError("header has no format")
+			}
+			return header, elements, nil
+
+		default:
+			return nil, nil, formatError("unknown header line %q", line)
+		}
+	}
+}
+
+func writeHeader(w io.Writer, header *Header, vertices int) error {
+	positionType := header.PositionType
+	if positionType == "" {
+		positionType = "float"
+	}
+	if typeSizes[positionType] == 0 {
+		return fmt.Errorf("ply: unknown position type %q", positionType)
+	}
+	if header.Format < ASCII || header.Format > BinaryBigEndian {
+		return fmt.Errorf("ply: unknown format %d", header.Format)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "ply\nformat %v 1.0\n", formatNames[header.Format])
+	for _, comment := range header.Comments {
+		if strings.ContainsAny(comment, "\r\n") {
+			return fmt.Errorf("ply: comment %q spans several lines", comment)
+		}
+		fmt.Fprintf(&b, "comment %v\n", comment)
+	}
+
+	fmt.Fprintf(&b, "element vertex %d\n", vertices)
+	for _, axis := range []string{"x", "y", "z"} {
+		fmt.Fprintf(&b, "property %v %v\n", positionType, axis)
+	}
+	for _, p := range header.Properties {
+		if typeSizes[p.Type] == 0 {
+			return fmt.Errorf("ply: unknown type %q of property %q", p.Type, p.Name)
+		}
+		if p.Name == "" || strings.ContainsAny(p.Name, " \t\r\n") {
+			return fmt.Errorf("ply: invalid property name %q", p.Name)
+		}
+		fmt.Fprintf(&b, "property %v %v\n", p.Type, p.Name)
+	}
+	b.WriteString("end_header\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,154 @@
+package ply
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/bjnsn/go-octree/octree"
+	"github.com/bjnsn/go-octree/octree/internal/testutil"
+)
+
+var equals = testutil.Equals
+
+const cube = `ply
+format ascii 1.0
+comment made by hand
+obj_info not kept
+element camera 1
+property float view_px
+element vertex 4
+property float x
+property float y
+property double z
+property uchar red
+property uchar green
+property uchar blue
+property float intensity
+element face 2
+property list uchar int vertex_indices
+end_header
+0.5
+0 0 0 255 0 0 0.25
+1 0 0 0 255 0 0.5
+
+0 1 0.5 0 0 255 1
+1 1 1 255 255 255 0
+3 0 1 2
+3 1 2 3
+`
+
+func TestReadsASCII(t *testing.T) {
+	reader, err := NewReader(strings.NewReader(cube))
+	equals(t, nil, err)
+	equals(t, &Header{
+		Format:       ASCII,
+		Vertices:     4,
+		PositionType: "double",
+		Properties:   []Property{{"red", "uchar"}, {"green", "uchar"}, {"blue", "uchar"}, {"intensity", "float"}},
+		Comments:     []string{"made by hand"},
+	}, reader.Header())
+
+	vertex, err := reader.Read()
+	equals(t, nil, err)
+	equals(t, Vertex{octree.Vector3f{0, 0, 0}, []float64{255, 0, 0, 0.25}}, vertex)
+
+	for i := 0; i < 3; i++ {
+		_, err = reader.Read()
+		equals(t, nil, err)
+	}
+	_, err = reader.Read()
+	equals(t, io.EOF, err)
+
+	// into a tree
+	o, header, err := Load(strings.NewReader(cube), octree.WithCapacity(2))
+	equals(t, nil, err)
+	equals(t, 4, header.Vertices)
+	equals(t, []Vertex{{octree.Vector3f{0, 1, 0.5}, []float64{0, 0, 255, 1}}}, o.ElementsAt(octree.Vector3f{0, 1, 0.5}))
+	equals(t, nil, o.Validate())
+}
+
+func TestRoundTrips(t *testing.T) {
+	_, header, err := Load(strings.NewReader(cube))
+	equals(t, nil, err)
+
+	for _, format := range []Format{ASCII, BinaryLittleEndian, BinaryBigEndian} {
+		header.Format = format
+		header.Vertices = 2
+		vertices := []Vertex{
+			{octree.Vector3f{0.1, -2, 3e10}, []float64{1, 2, 3, 0.5}},
+			{octree.Vector3f{1.0 / 3, 0, 0}, []float64{255, 0, 128, -1.5}},
+		}
+
+		var buf bytes.Buffer
+		equals(t, nil, Write(&buf, header, vertices))
+
+		reader, err := NewReader(&buf)
+		equals(t, nil, err)
+		equals(t, header, reader.Header())
+		for i := range vertices {
+			vertex, err := reader.Read()
+			equals(t, nil, err)
+			equals(t, vertices[i], vertex)
+		}
+		_, err = reader.Read()
+		equals(t, io.EOF, err)
+	}
+}
+
+func TestWritesQueryResults(t *testing.T) {
+	o := octree.CreateOctree[Vertex](octree.Vector3f{0, 0, 0}, octree.Vector3f{1, 1, 1})
+	header, err := ReadInto(strings.NewReader(cube), o)
+	equals(t, nil, err)
+
+	// positions of type float, as the header of the file written says
+	header = &Header{Format: BinaryLittleEndian, Properties: header.Properties}
+	found := o.ElementsIn(octree.NewBox(octree.Vector3f{0, 0, 0}, octree.Vector3f{1, 0.5, 0.5}))
+	var buf bytes.Buffer
+	equals(t, nil, Write(&buf, header, found))
+	equals(t, true, strings.HasPrefix(buf.String(), "ply\nformat binary_little_endian 1.0\nelement vertex 2\nproperty float x\n"))
+
+	written := octree.CreateOctree[Vertex](octree.Vector3f{0, 0, 0}, octree.Vector3f{1, 1, 1})
+	_, err = ReadInto(&buf, written)
+	equals(t, nil, err)
+	equals(t, o.ElementsAt(octree.Vector3f{1, 0, 0}), written.ElementsAt(octree.Vector3f{1, 0, 0}))
+	equals(t, 2, written.Stats().Elements)
+}
+
+func TestReportsErrors(t *testing.T) {
+	for _, data := range []string{
+		"",
+		"obj\n",
+		"ply\nformat ascii 2.0\nend_header\n",
+		"ply\nformat ascii 1.0\nelement vertex 1\nproperty float x\nproperty float y\nend_header\n0 0\n",
+		"ply\nformat ascii 1.0\nelement vertex 1\nproperty float x\nproperty float y\nproperty half z\nend_header\n",
+		"ply\nformat ascii 1.0\nelement face 1\nproperty list uchar int vertex_indices\nend_header\n0\n",
+		"ply\nformat ascii 1.0\nelement vertex 1\nproperty float x\nproperty float y\nproperty float z\n",
+	} {
+		_, err := NewReader(strings.NewReader(data))
+		equals(t, true, errors.Is(err, ErrFormat))
+	}
+
+	for _, data := range []string{
+		"0 0\n",
+		"0 0 0 0\n",
+		"0 0 a\n",
+		"",
+	} {
+		_, _, err := Load(strings.NewReader("ply\nformat ascii 1.0\nelement vertex 2\nproperty float x\nproperty float y\nproperty float z\nend_header\n0 0 0\n" + data))
+		equals(t, true, errors.Is(err, ErrFormat))
+	}
+
+	_, _, err := Load(strings.NewReader("ply\nformat binary_big_endian 1.0\nelement vertex 1\nproperty float x\nproperty float y\nproperty float z\nend_header\n\x00\x00"))
+	equals(t, true, errors.Is(err, ErrFormat))
+
+	o := octree.CreateOctree[Vertex](octree.Vector3f{0, 0, 0}, octree.Vector3f{0.5, 0.5, 0.5})
+	_, err = ReadInto(strings.NewReader(cube), o)
+	equals(t, true, errors.Is(err, octree.ErrOutOfBounds))
+
+	header := &Header{Properties: []Property{{"red", "uchar"}}}
+	equals(t, false, Write(io.Discard, header, []Vertex{{octree.Vector3f{}, []float64{256}}}) == nil)
+	equals(t, false, Write(io.Discard, header, []Vertex{{octree.Vector3f{}, nil}}) == nil)
+}
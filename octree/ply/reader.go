@@ -0,0 +1,294 @@
+package ply
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/bjnsn/go-octree/octree"
+	"github.com/bjnsn/go-octree/octree/internal/cloud"
+)
+
+// Reader Reads the vertices of a PLY file one at a time. Elements that
+// follow the vertices, such as faces, are never read.
+type Reader struct {
+	r      *bufio.Reader
+	header *Header
+	order  binary.ByteOrder
+	vertex element
+	// axes holds the index of the x, y and z properties of the vertex element
+	axes [3]int
+	// values holds the index in Vertex.Values of each property of the
+	// vertex element, or -1 for those giving the position of the vertex.
+	values []int
+	read   int
+	buf    [8]byte
+}
+
+// NewReader Reads the header of the PLY file read from r, and any elements
+// preceding its vertices, so that Read returns its first vertex. Returns an
+// error wrapping ErrFormat if r doesn't hold a PLY file with vertices.
+func NewReader(r io.Reader) (*Reader, error) {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+
+	header, elements, err := readHeader(br)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := &Reader{r: br, header: header, axes: [3]int{-1, -1, -1}}
+	switch header.Format {
+	case BinaryLittleEndian:
+		reader.order = binary.LittleEndian
+	case BinaryBigEndian:
+		reader.order = binary.BigEndian
+	}
+
+	found := false
+	for _, e := range elements {
+		if e.name == "vertex" {
+			reader.vertex, found = e, true
+			break
+		}
+
+		// skip those that come first, e.g. a camera
+		for i := 0; i < e.count; i++ {
+			if err := reader.skip(&e); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if !found {
+		return nil, formatError("file has no vertex element")
+	}
+
+	for i, p := range reader.vertex.properties {
+		if p.countType != "" {
+			return nil, formatError("list property %q of vertices is not supported", p.name)
+		}
+
+		axis := strings.Index("xyz", p.name)
+		if len(p.name) == 1 && axis >= 0 {
+			reader.axes[axis] = i
+			if header.PositionType == "" || typeSizes[p.typ] > typeSizes[header.PositionType] {
+				header.PositionType = p.typ
+			}
+			reader.values = append(reader.values, -1)
+			continue
+		}
+
+		reader.values = append(reader.values, len(header.Properties))
+		header.Properties = append(header.Properties, Property{Name: p.name, Type: p.typ})
+	}
+
+	for i, axis := range reader.axes {
+		if axis < 0 {
+			return nil, formatError("vertices have no %c property", "xyz"[i])
+		}
+	}
+
+	header.Vertices = reader.vertex.count
+	return reader, nil
+}
+
+// Header Returns the header of the file, describing its vertices. The type of
+// their position is the widest of those of their x, y and z properties.
+func (r *Reader) Header() *Header {
+	return r.header
+}
+
+// Read Reads the next vertex, returning io.EOF after the last one, or an error
+// wrapping ErrFormat if the vertex can't be read.
+func (r *Reader) Read() (Vertex, error) {
+	if r.read == r.vertex.count {
+		return Vertex{}, io.EOF
+	}
+
+	vertex := Vertex{Values: make([]float64, len(r.header.Properties))}
+	var values []float64
+	var err error
+
+	if r.order == nil {
+		values, err = r.readLine(&r.vertex)
+	} else {
+		values = make([]float64, len(r.vertex.properties))
+		for i, p := range r.vertex.properties {
+			if values[i], err = r.readBinary(p.typ); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return Vertex{}, fmt.Errorf("%w (vertex %d)", err, r.read)
+	}
+
+	for i, index := range r.values {
+		if index >= 0 {
+			vertex.Values[index] = values[i]
+		}
+	}
+	for i, axis := range r.axes {
+		vertex.Position[i] = values[axis]
+	}
+
+	r.read++
+	return vertex, nil
+}
+
+// ReadInto Streams the vertices of the PLY file read from r into the tree,
+// returning the header of the file. Returns an error wrapping ErrFormat if the
+// file can't be read, or the *octree.PointError of the first vertex that can't
+// be added, along with its index; the vertices before it stay in the tree.
+func ReadInto(r io.Reader, o *octree.Octree[Vertex]) (*Header, error) {
+	reader, err := NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cloud.ReadInto(o, reader.next, reader.where); err != nil {
+		return nil, err
+	}
+	return reader.Header(), nil
+}
+
+// Load Reads the vertices of the PLY file read from r and bulk loads them
+// with octree.BuildOctree into a tree fitted to the box around them, returning
+// it along with the header of the file. Use ReadInto for a tree with bounds
+// of your own, or to gather several scans into one tree.
+func Load(r io.Reader, opts ...octree.Option) (*octree.Octree[Vertex], *Header, error) {
+	reader, err := NewReader(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	o, err := cloud.Build(reader.next, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return o, reader.Header(), nil
+}
+
+func (r *Reader) next() (Vertex, octree.Vector3f, error) {
+	vertex, err := r.Read()
+	return vertex, vertex.Position, err
+}
+
+func (r *Reader) where() string {
+	return fmt.Sprintf("ply: adding vertex %d", r.read-1)
+}
+
+func (r *Reader) skip(e *element) error {
+	// read past one of the element's records
+	if r.order == nil {
+		_, err := r.readLine(e)
+		return err
+	}
+
+	for _, p := range e.properties {
+		count := 1
+		if p.countType != "" {
+			length, err := r.readBinary(p.countType)
+			if err != nil {
+				return err
+			}
+			count = int(length)
+		}
+
+		if _, err := r.r.Discard(count * typeSizes[p.typ]); err != nil {
+			return formatError("%v element ends early", e.name)
+		}
+	}
+
+	return nil
+}
+
+func (r *Reader) readLine(e *element) ([]float64, error) {
+	// read one of the element's records from a line of an ASCII
+	// file, returning the values of its single valued properties.
+
+	var fields []string
+	for len(fields) == 0 {
+		line, err := r.r.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return nil, formatError("%v element ends early", e.name)
+		}
+		fields = strings.Fields(line)
+	}
+
+	values := make([]float64, 0, len(e.properties))
+	for _, p := range e.properties {
+		if p.countType != "" {
+			// skip the items of lists
+			if len(fields) == 0 {
+				return nil, formatError("%v element has too few values", e.name)
+			}
+			length, err := strconv.ParseUint(fields[0], 10, 32)
+			if err != nil || uint64(len(fields)) <= length {
+				return nil, formatError("invalid list %q of %v element", p.name, e.name)
+			}
+			fields = fields[1+length:]
+			continue
+		}
+
+		if len(fields) == 0 {
+			return nil, formatError("%v element has too few values", e.name)
+		}
+		value, err := parseValue(p.typ, fields[0])
+		if err != nil {
+			return nil, formatError("invalid %v %q of %v element", p.typ, fields[0], e.name)
+		}
+		values = append(values, value)
+		fields = fields[1:]
+	}
+
+	if len(fields) != 0 {
+		return nil, formatError("%v element has too many values", e.name)
+	}
+
+	return values, nil
+}
+
+func parseValue(typ, field string) (float64, error) {
+	bits := typeSizes[typ] * 8
+	switch typ {
+	case "float", "double":
+		return strconv.ParseFloat(field, bits)
+	case "uchar", "ushort", "uint":
+		v, err := strconv.ParseUint(field, 10, bits)
+		return float64(v), err
+	}
+	v, err := strconv.ParseInt(field, 10, bits)
+	return float64(v), err
+}
+
+func (r *Reader) readBinary(typ string) (float64, error) {
+	b := r.buf[:typeSizes[typ]]
+	if _, err := io.ReadFull(r.r, b); err != nil {
+		return 0, formatError("file ends early")
+	}
+
+	switch typ {
+	case "char":
+		return float64(int8(b[0])), nil
+	case "uchar":
+		return float64(b[0]), nil
+	case "short":
+		return float64(int16(r.order.Uint16(b))), nil
+	case "ushort":
+		return float64(r.order.Uint16(b)), nil
+	case "int":
+		return float64(int32(r.order.Uint32(b))), nil
+	case "uint":
+		return float64(r.order.Uint32(b)), nil
+	case "float":
+		return float64(math.Float32frombits(r.order.Uint32(b))), nil
+	}
+	return math.Float64frombits(r.order.Uint64(b)), nil
+}
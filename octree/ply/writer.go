@@ -0,0 +1,123 @@
+package ply
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// Write Writes the vertices to w as a PLY file with the format, position type,
+// properties and comments of the header; e.g. the header of the file they
+// were read from, or one describing just their position. Returns an error if
+// a vertex doesn't have a value for each property, or has a value that its
+// type can't hold.
+func Write(w io.Writer, header *Header, vertices []Vertex) error {
+	bw := bufio.NewWriter(w)
+	if err := writeHeader(bw, header, len(vertices)); err != nil {
+		return err
+	}
+
+	positionType := canonical(header.PositionType)
+	if positionType == "" {
+		positionType = "float"
+	}
+
+	var order binary.AppendByteOrder
+	switch header.Format {
+	case BinaryLittleEndian:
+		order = binary.LittleEndian
+	case BinaryBigEndian:
+		order = binary.BigEndian
+	}
+
+	var buf []byte
+	for i := range vertices {
+		vertex := &vertices[i]
+		if len(vertex.Values) != len(header.Properties) {
+			return fmt.Errorf("ply: vertex %d has %d values for %d properties", i, len(vertex.Values), len(header.Properties))
+		}
+
+		buf = buf[:0]
+		for j := 0; j < 3+len(vertex.Values); j++ {
+			var typ, name string
+			var value float64
+			if j < 3 {
+				typ, name, value = positionType, "xyz"[j:j+1], vertex.Position[j]
+			} else {
+				typ, name, value = canonical(header.Properties[j-3].Type), header.Properties[j-3].Name, vertex.Values[j-3]
+			}
+
+			if !fits(typ, value) {
+				return fmt.Errorf("ply: %v %v of vertex %d can't hold %v", typ, name, i, value)
+			}
+
+			if order == nil {
+				if j > 0 {
+					buf = append(buf, ' ')
+				}
+				buf = appendText(buf, typ, value)
+			} else {
+				buf = appendBinary(buf, order, typ, value)
+			}
+		}
+		if order == nil {
+			buf = append(buf, '\n')
+		}
+
+		if _, err := bw.Write(buf); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+func fits(typ string, value float64) bool {
+	// whether a value of the type can hold the value exactly,
+	// or as near as floats do
+	var min, max float64
+	switch typ {
+	case "float", "double":
+		return true
+	case "char":
+		min, max = math.MinInt8, math.MaxInt8
+	case "uchar":
+		min, max = 0, math.MaxUint8
+	case "short":
+		min, max = math.MinInt16, math.MaxInt16
+	case "ushort":
+		min, max = 0, math.MaxUint16
+	case "int":
+		min, max = math.MinInt32, math.MaxInt32
+	case "uint":
+		min, max = 0, math.MaxUint32
+	}
+	return value >= min && value <= max && value == math.Trunc(value)
+}
+
+func appendText(buf []byte, typ string, value float64) []byte {
+	switch typ {
+	case "float":
+		return strconv.AppendFloat(buf, value, 'g', -1, 32)
+	case "double":
+		return strconv.AppendFloat(buf, value, 'g', -1, 64)
+	}
+	return strconv.AppendInt(buf, int64(value), 10)
+}
+
+func appendBinary(buf []byte, order binary.AppendByteOrder, typ string, value float64) []byte {
+	switch typ {
+	case "char", "uchar":
+		return append(buf, byte(int64(value)))
+	case "short", "ushort":
+		return order.AppendUint16(buf, uint16(int64(value)))
+	case "int", "uint":
+		return order.AppendUint32(buf, uint32(int64(value)))
+	case "float":
+		return order.AppendUint32(buf, math.Float32bits(float32(value)))
+	}
+	return order.AppendUint64(buf, math.Float64bits(value))
+}
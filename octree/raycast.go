@@ -0,0 +1,139 @@
+package octree
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+)
+
+// RayHit An element found by Raycast, along with the point it was added
+// at, its distance along the ray and its distance from the ray.
+type RayHit[T any] struct {
+	Element  T
+	Point    Vector3f
+	Distance float64
+	Offset   float64
+}
+
+// Raycast Retrieves the elements whose points lie within tolerance of
+// the ray from origin in direction dir, up to maxDist along the ray.
+// Hits are sorted by ascending distance along the ray. Elements added
+// with AddBox are not considered.
+func (o *Octree[T]) Raycast(origin, dir Vector3f, maxDist, tolerance float64) []RayHit[T] {
+	r, ok := makeRay(&origin, &dir, maxDist, tolerance)
+	if !ok {
+		return nil
+	}
+
+	return o.root.raycast(&r, false)
+}
+
+// RaycastFirst Retrieves the first element hit by the ray as described by
+// Raycast, stopping as soon as no closer element can be found. Returns false
+// if nothing was hit.
+func (o *Octree[T]) RaycastFirst(origin, dir Vector3f, maxDist, tolerance float64) (RayHit[T], bool) {
+	r, ok := makeRay(&origin, &dir, maxDist, tolerance)
+	if !ok {
+		return RayHit[T]{}, false
+	}
+
+	hits := o.root.raycast(&r, true)
+	if len(hits) == 0 {
+		return RayHit[T]{}, false
+	}
+	return hits[0], true
+}
+
+func (n *Node[T]) raycast(r *ray, first bool) []RayHit[T] {
+	// visit nodes in the order the ray enters them. Any point within
+	// tolerance of the ray is inside its node's box expanded by the
+	// tolerance, so a hit can never lie before the entry distance
+	// of the node it is in.
+	hits := []RayHit[T]{}
+	queue := &nodeQueue[T]{}
+	if distance, ok := r.enters(&n.box); ok {
+		heap.Push(queue, nodeDistance[T]{node: n, distance: distance})
+	}
+
+	for queue.Len() > 0 {
+		next := heap.Pop(queue).(nodeDistance[T])
+		if first && len(hits) > 0 && next.distance > hits[0].Distance {
+			break
+		}
+
+		if next.node.hasChildren {
+			for _, child := range next.node.children {
+				if distance, ok := r.enters(&child.box); ok {
+					heap.Push(queue, nodeDistance[T]{node: child, distance: distance})
+				}
+			}
+			continue
+		}
+
+		for i := range next.node.entries {
+			e := &next.node.entries[i]
+			distance, offset, ok := r.passes(&e.point)
+			if !ok || (first && len(hits) > 0 && distance >= hits[0].Distance) {
+				continue
+			}
+
+			if first {
+				hits = hits[:0]
+			}
+			for _, element := range e.elements {
+				hits = append(hits, RayHit[T]{Element: element, Point: e.point, Distance: distance, Offset: offset})
+			}
+		}
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Distance < hits[j].Distance
+	})
+
+	return hits
+}
+
+type ray struct {
+	origin    Vector3f
+	dir       Vector3f
+	maxDist   float64
+	tolerance float64
+}
+
+func makeRay(origin, dir *Vector3f, maxDist, tolerance float64) (ray, bool) {
+	length := dir.Length()
+	if length == 0 || math.IsNaN(length) || maxDist < 0 || tolerance < 0 {
+		return ray{}, false
+	}
+
+	return ray{origin: *origin, dir: dir.Scale(1 / length), maxDist: maxDist, tolerance: tolerance}, true
+}
+
+func (r *ray) enters(box *Box) (float64, bool) {
+	// returns the distance along the ray at which it enters
+	// the box, expanded by the tolerance.
+	grown := Box{
+		min: Vector3f{box.min[0] - r.tolerance, box.min[1] - r.tolerance, box.min[2] - r.tolerance},
+		max: Vector3f{box.max[0] + r.tolerance, box.max[1] + r.tolerance, box.max[2] + r.tolerance},
+	}
+
+	near, far, ok := grown.IntersectsRay(&r.origin, &r.dir)
+	if !ok || far < 0 || near > r.maxDist {
+		return 0, false
+	}
+
+	return math.Max(near, 0), true
+}
+
+func (r *ray) passes(point *Vector3f) (float64, float64, bool) {
+	// returns the distance along the ray of the closest
+	// approach to the point, and how close it gets.
+	toPoint := point.Minus(&r.origin)
+	distance := math.Min(math.Max(toPoint.Dot(&r.dir), 0), r.maxDist)
+
+	along := r.dir.Scale(distance)
+	closest := r.origin.Plus(&along)
+	offset := closest.Distance(point)
+
+	return distance, offset, offset <= r.tolerance
+}
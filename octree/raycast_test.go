@@ -0,0 +1,100 @@
+package octree
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestRaycastOrdersByDistance(t *testing.T) {
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+
+	o.Add(1, Vector3f{0.75, 0.5, 0.5})
+	o.Add(2, Vector3f{0.25, 0.5, 0.5})
+	o.Add(3, Vector3f{0.5, 0.5, 0.5})
+	o.Add(4, Vector3f{0.5, 0.75, 0.5})
+	o.Add(5, Vector3f{0.5, 0.5, 0.5})
+
+	hits := o.Raycast(Vector3f{0, 0.5, 0.5}, Vector3f{2, 0, 0}, 10, 0.1)
+	equals(t, 4, len(hits))
+	equals(t, 2, hits[0].Element)
+	equals(t, 0.25, hits[0].Distance)
+	equals(t, 0.0, hits[0].Offset)
+	equals(t, 3, hits[1].Element)
+	equals(t, 5, hits[2].Element)
+	equals(t, 1, hits[3].Element)
+
+	// limited by distance
+	equals(t, 3, len(o.Raycast(Vector3f{0, 0.5, 0.5}, Vector3f{1, 0, 0}, 0.6, 0.1)))
+
+	// wider tolerance
+	equals(t, 5, len(o.Raycast(Vector3f{0, 0.5, 0.5}, Vector3f{1, 0, 0}, 10, 0.25)))
+
+	// from outside the tree, in the opposite direction
+	hits = o.Raycast(Vector3f{2, 0.5, 0.5}, Vector3f{-1, 0, 0}, 10, 0)
+	equals(t, 4, len(hits))
+	equals(t, 1, hits[0].Element)
+	equals(t, 1.25, hits[0].Distance)
+
+	// pointing away, or invalid
+	equals(t, 0, len(o.Raycast(Vector3f{2, 0.5, 0.5}, Vector3f{1, 0, 0}, 10, 0.1)))
+	equals(t, 0, len(o.Raycast(Vector3f{0, 0.5, 0.5}, Vector3f{0, 0, 0}, 10, 0.1)))
+}
+
+func TestRaycastFirst(t *testing.T) {
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+
+	_, ok := o.RaycastFirst(Vector3f{0, 0.5, 0.5}, Vector3f{1, 0, 0}, 10, 0.1)
+	equals(t, false, ok)
+
+	o.Add(1, Vector3f{0.75, 0.5, 0.5})
+	o.Add(2, Vector3f{0.3, 0.55, 0.5})
+	o.Add(3, Vector3f{0.1, 0.9, 0.5})
+
+	hit, ok := o.RaycastFirst(Vector3f{0, 0.5, 0.5}, Vector3f{1, 0, 0}, 10, 0.1)
+	equals(t, true, ok)
+	equals(t, 2, hit.Element)
+	equals(t, Vector3f{0.3, 0.55, 0.5}, hit.Point)
+
+	hit, ok = o.RaycastFirst(Vector3f{1, 0.5, 0.5}, Vector3f{-1, 0, 0}, 10, 0.1)
+	equals(t, true, ok)
+	equals(t, 1, hit.Element)
+}
+
+func TestRaycastMatchesBruteForce(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+	points := make([]Vector3f, 500)
+	for i := range points {
+		points[i] = Vector3f{r.Float64(), r.Float64(), r.Float64()}
+		o.Add(i, points[i])
+	}
+
+	for q := 0; q < 20; q++ {
+		origin := Vector3f{r.Float64()*3 - 1, r.Float64()*3 - 1, r.Float64()*3 - 1}
+		target := Vector3f{r.Float64(), r.Float64(), r.Float64()}
+		dir := target.Minus(&origin)
+		ry, _ := makeRay(&origin, &dir, 3, 0.05)
+
+		exp := []float64{}
+		for i := range points {
+			if distance, _, ok := ry.passes(&points[i]); ok {
+				exp = append(exp, distance)
+			}
+		}
+		sort.Float64s(exp)
+
+		hits := o.Raycast(origin, dir, 3, 0.05)
+		act := []float64{}
+		for _, hit := range hits {
+			act = append(act, hit.Distance)
+		}
+		equals(t, exp, act)
+
+		first, ok := o.RaycastFirst(origin, dir, 3, 0.05)
+		equals(t, len(exp) > 0, ok)
+		if ok {
+			equals(t, exp[0], first.Distance)
+		}
+	}
+}
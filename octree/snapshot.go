@@ -0,0 +1,166 @@
+package octree
+
+import "sync/atomic"
+
+// generations hands out the generation of each version of a tree.
+var generations atomic.Uint64
+
+func nextGeneration() uint64 {
+	return generations.Add(1)
+}
+
+// Snapshot Returns a copy of the tree in constant time. The copy and the tree
+// share all of their nodes until either is modified, when the nodes on the
+// path to the change are copied (copy-on-write), so that neither sees the
+// other's changes. Both remain ordinary trees that can be read and modified.
+// Taking a snapshot counts as modifying the tree, but the snapshot can then
+// be read from other goroutines while the tree continues to be modified.
+func (o *Octree[T]) Snapshot() *Octree[T] {
+	snapshot := *o
+	snapshot.gen = nextGeneration()
+
+	// the nodes are now shared, so neither may modify them in place
+	o.gen = nextGeneration()
+
+	return &snapshot
+}
+
+// Added Returns a new version of the tree with the element added at the
+// specified point, along with the node holding it in that version, as Add
+// does. This tree is left unchanged and shares all but the nodes on the path
+// to the point with the new version.
+func (o *Octree[T]) Added(element T, point Vector3f) (*Octree[T], *Node[T]) {
+	next := o.Snapshot()
+	return next, next.Add(element, point)
+}
+
+// Removed Returns a new version of the tree with the specified element
+// removed, as Remove does, and whether it was found. This tree is left
+// unchanged and shares all but the nodes on the path to the element with
+// the new version.
+func (o *Octree[T]) Removed(element T) (*Octree[T], bool) {
+	next := o.Snapshot()
+	return next, next.Remove(element)
+}
+
+// RemovedUsing Returns a new version of the tree with the specified element
+// removed, as RemoveUsing does, and whether it was found. node may be from
+// this or any earlier version of the tree.
+func (o *Octree[T]) RemovedUsing(element T, node *Node[T]) (*Octree[T], bool) {
+	next := o.Snapshot()
+	return next, next.RemoveUsing(element, node)
+}
+
+// Moved Returns a new version of the tree with the element moved to the
+// specified point, along with the node holding it in that version, as Move
+// does. node may be from this or any earlier version of the tree. This tree
+// is left unchanged and shares all but the nodes on the paths to the old and
+// new points with the new version.
+func (o *Octree[T]) Moved(element T, node *Node[T], point Vector3f) (*Octree[T], *Node[T]) {
+	next := o.Snapshot()
+	return next, next.Move(element, node, point)
+}
+
+func (n *Node[T]) copyFor(gen uint64, parent *Node[T]) *Node[T] {
+	// a copy of the node that the tree with generation gen can modify
+	c := *n
+	c.gen = gen
+	c.parent = parent
+	c.entries = cloneEntries(n.entries)
+	c.volumes = append([]volume[T](nil), n.volumes...)
+	c.children = append([]*Node[T](nil), n.children...)
+	return &c
+}
+
+func cloneEntries[T any](entries []entry[T]) []entry[T] {
+	// the element slices are modified in place, so copy them as well
+	if entries == nil {
+		return nil
+	}
+
+	clone := make([]entry[T], len(entries))
+	for i := range entries {
+		clone[i] = entry[T]{point: entries[i].point, elements: append([]T(nil), entries[i].elements...)}
+	}
+
+	return clone
+}
+
+func (o *Octree[T]) ownChild(n *Node[T], i int) *Node[T] {
+	// the ith child of n, copied first if it is shared with
+	// a snapshot. n must already belong to this tree.
+	if child := n.children[i]; child.gen != o.gen {
+		n.children[i] = child.copyFor(o.gen, n)
+	}
+
+	return n.children[i]
+}
+
+func (o *Octree[T]) writable(n *Node[T]) *Node[T] {
+	// the node of this tree that can be modified in place in
+	// place of n, which must be in the tree, copying it and its
+	// ancestors where they are shared with a snapshot.
+
+	if n.gen == o.gen {
+		return n
+	}
+
+	path, ok := o.root.pathTo(n, nil)
+	if !ok {
+		panic("octree: node is not in the tree")
+	}
+
+	if o.root.gen != o.gen {
+		o.root = o.root.copyFor(o.gen, nil)
+	}
+
+	node := o.root
+	for _, i := range path {
+		node = o.ownChild(node, i)
+	}
+
+	return node
+}
+
+func (n *Node[T]) pathTo(target *Node[T], path []int) ([]int, bool) {
+	// the indices of the children leading from n to target. Nodes
+	// shared between versions of a tree don't know their parent in
+	// every version, so the path is found by descending instead.
+
+	if n == target {
+		return path, true
+	}
+
+	for i, child := range n.children {
+		if child.box.Contains(&target.box) {
+			if found, ok := child.pathTo(target, append(path, i)); ok {
+				return found, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+func (o *Octree[T]) resolve(n *Node[T]) (*Node[T], *Box) {
+	// the node of this tree a search starting from the node n
+	// should start from instead, and the region it is confined to:
+	// n itself, or the ancestor it has been collapsed into. A node
+	// from another version of the tree that isn't part of this one
+	// may have had its contents moved elsewhere (e.g. as the tree
+	// grew), so it is searched for within its box from the root.
+
+	if n == nil {
+		return nil, nil
+	}
+
+	if n.gen == o.gen {
+		return n.live(), nil
+	}
+
+	if _, ok := o.root.pathTo(n, nil); ok {
+		return n, nil
+	}
+
+	return o.root, &n.box
+}
@@ -0,0 +1,230 @@
+package octree
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestSnapshotIsUnchangedByTree(t *testing.T) {
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+	all := Box{Vector3f{0, 0, 0}, Vector3f{1, 1, 1}}
+
+	node1 := o.Add(1, Vector3f{0.1, 0.1, 0.1})
+	o.Add(2, Vector3f{0.9, 0.9, 0.9})
+	o.Add(3, Vector3f{0.9, 0.9, 0.9})
+	snapshot := o.Snapshot()
+	equals(t, o.root, snapshot.root)
+
+	// subdivide, add at a shared point, remove and collapse
+	o.Add(4, Vector3f{0.2, 0.2, 0.2})
+	o.Add(5, Vector3f{0.9, 0.9, 0.9})
+	equals(t, true, o.Remove(2))
+	equals(t, true, o.RemoveUsing(1, node1))
+	equals(t, []int{3, 4, 5}, sorted(o.ElementsIn(all)))
+	checkCounts(t, o.root)
+
+	equals(t, []int{1, 2, 3}, sorted(snapshot.ElementsIn(all)))
+	equals(t, []int{2, 3}, snapshot.ElementsAt(Vector3f{0.9, 0.9, 0.9}))
+	equals(t, []int{1}, snapshot.ElementsAt(Vector3f{0.1, 0.1, 0.1}))
+	checkCounts(t, snapshot.root)
+
+	// the snapshot is a tree in its own right
+	snapshot.Add(6, Vector3f{0.5, 0.5, 0.5})
+	equals(t, true, snapshot.RemoveUsing(1, node1))
+	equals(t, []int{2, 3, 6}, sorted(snapshot.ElementsIn(all)))
+	equals(t, []int{3, 4, 5}, sorted(o.ElementsIn(all)))
+}
+
+func TestPersistentOperations(t *testing.T) {
+	v0 := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+	all := Box{Vector3f{0, 0, 0}, Vector3f{1, 1, 1}}
+
+	v1, node1 := v0.Added(1, Vector3f{0.1, 0.1, 0.1})
+	v2, _ := v1.Added(2, Vector3f{0.9, 0.9, 0.9})
+	v3, node1 := v2.Moved(1, node1, Vector3f{0.9, 0.1, 0.9})
+	v4, ok := v3.Removed(2)
+	equals(t, true, ok)
+	_, ok = v4.Removed(2)
+	equals(t, false, ok)
+
+	equals(t, 0, len(v0.ElementsIn(all)))
+	equals(t, []int{1}, v1.ElementsIn(all))
+	equals(t, []int{1}, v2.ElementsAt(Vector3f{0.1, 0.1, 0.1}))
+	equals(t, []int{1, 2}, sorted(v3.ElementsIn(all)))
+	equals(t, []int{1}, v3.ElementsAt(Vector3f{0.9, 0.1, 0.9}))
+	equals(t, []int{1}, v4.ElementsIn(all))
+
+	// unchanged subtrees are shared
+	equals(t, v2.root.children[7], v3.root.children[7])
+	equals(t, true, v2.root.children[0] != v3.root.children[0])
+	equals(t, false, v4.root.hasChildren)
+	equals(t, true, v3.root.hasChildren)
+
+	// nodes from earlier versions can be used with later ones
+	v5, ok := v4.RemovedUsing(1, node1)
+	equals(t, true, ok)
+	equals(t, 0, len(v5.ElementsIn(all)))
+	equals(t, []int{1}, v4.ElementsIn(all))
+}
+
+func TestPersistentVersionsMatchBruteForce(t *testing.T) {
+	r := rand.New(rand.NewSource(13))
+	all := Box{Vector3f{-100, -100, -100}, Vector3f{100, 100, 100}}
+
+	// snap to a coarse grid so points often coincide and
+	// land on octant faces, occasionally outside the tree
+	randomPoint := func() Vector3f {
+		return Vector3f{float64(r.Intn(13)-2) / 8, float64(r.Intn(13)-2) / 8, float64(r.Intn(9)) / 8}
+	}
+
+	type version struct {
+		tree   *Octree[int]
+		points map[int]Vector3f
+		boxes  map[int]Box
+		nodes  map[int]*Node[int]
+	}
+
+	derive := func(v *version, tree *Octree[int]) *version {
+		next := &version{tree: tree, points: map[int]Vector3f{}, boxes: map[int]Box{}, nodes: map[int]*Node[int]{}}
+		for e, p := range v.points {
+			next.points[e] = p
+		}
+		for e, b := range v.boxes {
+			next.boxes[e] = b
+		}
+		for e, n := range v.nodes {
+			next.nodes[e] = n
+		}
+		return next
+	}
+
+	first := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1}, WithCapacity(2), WithGrowth(), WithLooseness(2))
+	versions := []*version{{tree: first, points: map[int]Vector3f{}, boxes: map[int]Box{}, nodes: map[int]*Node[int]{}}}
+
+	pick := func(m map[int]*Node[int]) (int, bool) {
+		keys := make([]int, 0, len(m))
+		for e := range m {
+			keys = append(keys, e)
+		}
+		if len(keys) == 0 {
+			return 0, false
+		}
+		sort.Ints(keys)
+		return keys[r.Intn(len(keys))], true
+	}
+
+	for i := 0; i < 600; i++ {
+		v := versions[r.Intn(len(versions))]
+		if r.Intn(3) == 0 {
+			// build on the most recent version more often
+			v = versions[len(versions)-1]
+		}
+
+		var next *version
+		e, found := pick(v.nodes)
+		_, isBox := v.boxes[e]
+
+		switch op := r.Intn(6); {
+		case op < 2 || !found:
+			point := randomPoint()
+			tree, node := v.tree.Added(i, point)
+			next = derive(v, tree)
+			next.points[i] = point
+			next.nodes[i] = node
+		case op == 2:
+			tree := v.tree.Snapshot()
+			min := randomPoint()
+			size := Vector3f{r.Float64() / 4, r.Float64() / 4, r.Float64() / 4}
+			box := Box{min, min.Plus(&size)}
+			next = derive(v, tree)
+			next.boxes[i] = box
+			next.nodes[i] = tree.AddBox(i, box)
+		case op == 3 && !isBox:
+			tree, ok := v.tree.RemovedUsing(e, v.nodes[e])
+			equals(t, true, ok)
+			next = derive(v, tree)
+			delete(next.points, e)
+			delete(next.nodes, e)
+		case op == 3:
+			tree, ok := v.tree.Removed(e)
+			equals(t, true, ok)
+			next = derive(v, tree)
+			delete(next.boxes, e)
+			delete(next.nodes, e)
+		case !isBox:
+			point := randomPoint()
+			tree, node := v.tree.Moved(e, v.nodes[e], point)
+			equals(t, true, node != nil)
+			next = derive(v, tree)
+			next.points[e] = point
+			next.nodes[e] = node
+		default:
+			tree := v.tree.Snapshot()
+			min := randomPoint()
+			box := Box{min, min.Plus(&Vector3f{0.1, 0.1, 0.1})}
+			next = derive(v, tree)
+			next.boxes[e] = box
+			next.nodes[e] = tree.MoveBox(e, v.nodes[e], box)
+			equals(t, true, next.nodes[e] != nil)
+		}
+
+		versions = append(versions, next)
+	}
+
+	for _, v := range versions {
+		var expected []int
+		for e, p := range v.points {
+			expected = append(expected, e)
+			found := false
+			for _, element := range v.tree.ElementsAt(p) {
+				found = found || element == e
+			}
+			equals(t, true, found)
+		}
+		for e, b := range v.boxes {
+			expected = append(expected, e)
+			center := b.min.Lerp(&b.max, 0.5)
+			found := false
+			for _, element := range v.tree.ElementsContaining(center) {
+				found = found || element == e
+			}
+			equals(t, true, found)
+		}
+
+		equals(t, sorted(expected), sorted(v.tree.ElementsIn(all)))
+		checkCounts(t, v.tree.root)
+	}
+}
+
+func TestSnapshotsReadWhileWriting(t *testing.T) {
+	c := CreateConcurrentOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1}, WithCapacity(4))
+	all := Box{Vector3f{0, 0, 0}, Vector3f{1, 1, 1}}
+	r := rand.New(rand.NewSource(3))
+	for i := 0; i < 500; i++ {
+		c.Add(i, Vector3f{r.Float64(), r.Float64(), r.Float64()})
+	}
+
+	var wg sync.WaitGroup
+	snapshot := c.Snapshot()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			if n := len(snapshot.ElementsIn(all)); n != 500 {
+				t.Errorf("snapshot holds %d elements", n)
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < 500; i += 2 {
+		c.Remove(i)
+		c.Add(500+i, Vector3f{r.Float64(), r.Float64(), r.Float64()})
+	}
+	wg.Wait()
+
+	equals(t, 500, len(c.ElementsIn(all)))
+	equals(t, 500, len(snapshot.ElementsIn(all)))
+}
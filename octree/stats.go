@@ -0,0 +1,104 @@
+package octree
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Stats Describes the shape of a tree, for tuning its capacity and spotting
+// degenerate trees; e.g. very deep ones built from clustered points.
+type Stats struct {
+	// Elements is the number of elements in the tree, including Volumes.
+	Elements int
+	// Volumes is the number of elements added with AddBox.
+	Volumes int
+	// Points is the number of distinct points elements were added at.
+	Points int
+
+	Nodes       int
+	Leaves      int
+	EmptyLeaves int
+
+	// MaxDepth and AverageDepth are those of the leaves,
+	// the root being at depth 0.
+	MaxDepth     int
+	AverageDepth float64
+	// DepthHistogram is the number of leaves at each depth.
+	DepthHistogram []int
+
+	// AverageElementsPerLeaf counts the elements at points in leaves.
+	AverageElementsPerLeaf float64
+
+	// MemoryBytes is an estimate of the memory used by the nodes and
+	// the slices they hold, excluding anything the elements refer to.
+	// Nodes shared with a snapshot are counted in full by both trees.
+	MemoryBytes int
+}
+
+// Stats Walks the tree to describe its shape.
+func (o *Octree[T]) Stats() Stats {
+	var s Stats
+	var leafDepths, leafElements int
+
+	nodeSize := int(reflect.TypeFor[Node[T]]().Size())
+	entrySize := int(reflect.TypeFor[entry[T]]().Size())
+	volumeSize := int(reflect.TypeFor[volume[T]]().Size())
+	elementSize := int(reflect.TypeFor[T]().Size())
+	pointerSize := int(reflect.TypeFor[*Node[T]]().Size())
+
+	var walk func(n *Node[T], depth int)
+	walk = func(n *Node[T], depth int) {
+		s.Nodes++
+		s.Volumes += len(n.volumes)
+		s.Points += len(n.entries)
+		s.MemoryBytes += nodeSize + cap(n.entries)*entrySize + cap(n.volumes)*volumeSize + cap(n.children)*pointerSize
+
+		elements := 0
+		for i := range n.entries {
+			elements += len(n.entries[i].elements)
+			s.MemoryBytes += cap(n.entries[i].elements) * elementSize
+		}
+		s.Elements += elements + len(n.volumes)
+
+		if n.hasChildren {
+			for _, child := range n.children {
+				walk(child, depth+1)
+			}
+			return
+		}
+
+		s.Leaves++
+		if len(n.entries) == 0 && len(n.volumes) == 0 {
+			s.EmptyLeaves++
+		}
+
+		for len(s.DepthHistogram) <= depth {
+			s.DepthHistogram = append(s.DepthHistogram, 0)
+		}
+		s.DepthHistogram[depth]++
+		s.MaxDepth = max(s.MaxDepth, depth)
+		leafDepths += depth
+		leafElements += elements
+	}
+
+	walk(o.root, 0)
+
+	s.AverageDepth = float64(leafDepths) / float64(s.Leaves)
+	s.AverageElementsPerLeaf = float64(leafElements) / float64(s.Leaves)
+
+	return s
+}
+
+// ToString Get a human readable report of the stats.
+func (s *Stats) ToString() string {
+	histogram := make([]string, len(s.DepthHistogram))
+	for depth, leaves := range s.DepthHistogram {
+		histogram[depth] = fmt.Sprintf("%d: %d", depth, leaves)
+	}
+
+	return fmt.Sprintf("Stats{\n  elements: %d (%d volumes) at %d points,\n  nodes: %d, leaves: %d (%d empty),\n"+
+		"  depth: max %d, average %.2f,\n  leaves by depth: [%v],\n  elements per leaf: %.2f,\n  memory: ~%d bytes\n}",
+		s.Elements, s.Volumes, s.Points, s.Nodes, s.Leaves, s.EmptyLeaves,
+		s.MaxDepth, s.AverageDepth, strings.Join(histogram, ", "), s.AverageElementsPerLeaf, s.MemoryBytes)
+}
@@ -0,0 +1,35 @@
+package octree
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+	s := o.Stats()
+	equals(t, 1, s.Nodes)
+	equals(t, 1, s.EmptyLeaves)
+	equals(t, []int{1}, s.DepthHistogram)
+
+	o.Add(1, Vector3f{0.1, 0.1, 0.1})
+	o.Add(2, Vector3f{0.1, 0.1, 0.1})
+	o.Add(3, Vector3f{0.9, 0.9, 0.9})
+	o.Add(5, Vector3f{0.9, 0.9, 0.6})
+	o.AddBox(4, Box{Vector3f{0.4, 0.4, 0.4}, Vector3f{0.6, 0.6, 0.6}})
+
+	s = o.Stats()
+	equals(t, 5, s.Elements)
+	equals(t, 1, s.Volumes)
+	equals(t, 3, s.Points)
+	equals(t, 17, s.Nodes)
+	equals(t, 15, s.Leaves)
+	equals(t, 12, s.EmptyLeaves)
+	equals(t, 2, s.MaxDepth)
+	equals(t, 23.0/15, s.AverageDepth)
+	equals(t, []int{0, 7, 8}, s.DepthHistogram)
+	equals(t, 4.0/15, s.AverageElementsPerLeaf)
+	equals(t, true, s.MemoryBytes > s.Nodes*int(reflect.TypeFor[Node[int]]().Size()))
+	equals(t, true, strings.Contains(s.ToString(), "nodes: 17, leaves: 15 (12 empty)"))
+}
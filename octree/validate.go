@@ -0,0 +1,95 @@
+package octree
+
+import "fmt"
+
+// Validate Walks the tree checking the invariants it relies on, returning an
+// error wrapping ErrCorrupt that describes the first that doesn't hold. That
+// branches have the eight children makeSubBoxes describes and hold no points;
+// that leaves hold each of their points only once, with at least one element,
+// and only points a search from the root would find in them; that volumes fit
+// within the bounds of their nodes; and that node counts and parents agree
+// with the tree's structure. It is intended for debugging and testing.
+func (o *Octree[T]) Validate() error {
+	if o.root.parent != nil {
+		return fmt.Errorf("%w: root %v has a parent", ErrCorrupt, o.root.box.ToString())
+	}
+
+	_, err := o.validate(o.root, 0, map[Vector3f]bool{})
+	return err
+}
+
+func (o *Octree[T]) validate(n *Node[T], depth int, seen map[Vector3f]bool) (int, error) {
+	// check the node and its descendants, returning
+	// the number of points and volumes they hold.
+
+	fail := func(format string, args ...interface{}) (int, error) {
+		return 0, fmt.Errorf("%w: node %v at depth %d %v", ErrCorrupt, n.box.ToString(), depth, fmt.Sprintf(format, args...))
+	}
+
+	if n.detached {
+		return fail("is detached")
+	}
+
+	for i := range n.volumes {
+		if !o.fits(n, &n.volumes[i].box) {
+			return fail("holds volume %v outside its bounds", n.volumes[i].box.ToString())
+		}
+	}
+
+	count := n.load()
+
+	if !n.hasChildren {
+		if len(n.children) != 0 {
+			return fail("is a leaf with %d children", len(n.children))
+		}
+
+		for i := range n.entries {
+			point := &n.entries[i].point
+			if len(n.entries[i].elements) == 0 {
+				return fail("holds no elements at %v", point.ToString())
+			}
+			if seen[*point] {
+				return fail("holds %v, which is held elsewhere", point.ToString())
+			}
+			seen[*point] = true
+
+			if !o.owns(n, point) {
+				return fail("holds %v, which a search from the root wouldn't find there", point.ToString())
+			}
+		}
+	} else {
+		if len(n.children) != 8 {
+			return fail("is a branch with %d children", len(n.children))
+		}
+		if len(n.entries) != 0 {
+			return fail("is a branch holding %d points", len(n.entries))
+		}
+		if !o.canSubdivide(n, depth) {
+			return fail("is a branch that can't be subdivided")
+		}
+
+		subBoxes := n.box.makeSubBoxes()
+		for i, child := range n.children {
+			if child.box != subBoxes[i] {
+				return fail("has child %d with box %v rather than %v", i, child.box.ToString(), subBoxes[i].ToString())
+			}
+
+			// nodes shared with a snapshot keep the parent they had there
+			if child.gen == n.gen && child.parent != n {
+				return fail("is not the parent of child %d", i)
+			}
+
+			childCount, err := o.validate(child, depth+1, seen)
+			if err != nil {
+				return 0, err
+			}
+			count += childCount
+		}
+	}
+
+	if count != n.count {
+		return fail("counts %d points and volumes, but holds %d", n.count, count)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,210 @@
+package octree
+
+import (
+	"errors"
+	"sort"
+	"testing"
+)
+
+func TestValidateFindsCorruption(t *testing.T) {
+	build := func() *Octree[int] {
+		o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+		o.Add(1, Vector3f{0.1, 0.1, 0.1})
+		o.Add(2, Vector3f{0.9, 0.9, 0.9})
+		o.Add(3, Vector3f{0.9, 0.1, 0.1})
+		o.AddBox(4, Box{Vector3f{0.4, 0.4, 0.4}, Vector3f{0.6, 0.6, 0.6}})
+		equals(t, nil, o.Validate())
+		return o
+	}
+
+	for name, corrupt := range map[string]func(o *Octree[int]){
+		"count": func(o *Octree[int]) { o.root.children[7].count++ },
+		"misplaced point": func(o *Octree[int]) {
+			o.root.children[7].entries[0].point = Vector3f{0.1, 0.9, 0.9}
+		},
+		"point on a shared face": func(o *Octree[int]) {
+			o.root.children[7].entries[0].point = Vector3f{0.5, 0.9, 0.9}
+		},
+		"duplicate point": func(o *Octree[int]) {
+			o.root.children[7].entries[0].point = Vector3f{0.9, 0.1, 0.1}
+			o.root.children[7].box = o.root.children[1].box
+		},
+		"child box": func(o *Octree[int]) { o.root.children[2].box.max[0] = 0.6 },
+		"points in a branch": func(o *Octree[int]) {
+			o.root.entries = o.root.children[0].entries
+			o.root.children[0].entries = nil
+		},
+		"children of a leaf": func(o *Octree[int]) { o.root.hasChildren = false },
+		"missing children":   func(o *Octree[int]) { o.root.children = o.root.children[:7] },
+		"no elements":        func(o *Octree[int]) { o.root.children[0].entries[0].elements = nil },
+		"parent":             func(o *Octree[int]) { o.root.children[3].parent = o.root.children[0] },
+		"volume":             func(o *Octree[int]) { o.root.volumes[0].box.max[0] = 2 },
+		"detached":           func(o *Octree[int]) { o.root.children[5].detached = true },
+	} {
+		o := build()
+		corrupt(o)
+		if err := o.Validate(); !errors.Is(err, ErrCorrupt) {
+			t.Errorf("%v: got %v", name, err)
+		}
+	}
+}
+
+func FuzzOperations(f *testing.F) {
+	f.Add([]byte{0, 0, 1, 2, 3, 0, 6, 6, 6, 0, 6, 6, 7, 2, 0, 0, 0, 4, 1, 2, 3, 3, 0, 5, 5})
+	f.Add([]byte{1, 0, 8, 8, 8, 0, 0, 0, 0, 6, 0, 0, 0, 0, 16, 16, 16, 4, 2, 8, 9, 5, 1, 0, 0, 1, 0, 0, 0})
+	f.Add([]byte{4, 0, 1, 1, 1, 0, 19, 19, 19, 4, 0, 0, 0, 5, 1, 2, 2, 6, 0, 0, 0, 3, 0, 0, 0, 2, 0, 0, 0})
+	f.Add([]byte{11, 5, 2, 3, 4, 5, 9, 9, 9, 6, 1, 1, 1, 5, 8, 8, 8, 7, 0, 4, 4, 2, 0, 1, 1, 3, 1, 0, 0})
+	f.Add([]byte{23, 0, 4, 4, 4, 0, 4, 4, 5, 0, 4, 5, 4, 0, 5, 4, 4, 4, 0, 4, 4, 2, 1, 4, 4, 1, 0, 0, 0})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if len(data) == 0 {
+			return
+		}
+
+		opts := []Option{WithCapacity(int(data[0]&3) + 1)}
+		growth := data[0]&4 != 0
+		if growth {
+			opts = append(opts, WithGrowth())
+		}
+		if data[0]&8 != 0 {
+			opts = append(opts, WithLooseness(2))
+		}
+		if data[0]&16 != 0 {
+			opts = append(opts, WithMaxDepth(3))
+		}
+
+		type model struct {
+			points map[int]Vector3f
+			boxes  map[int]Box
+			nodes  map[int]*Node[int]
+		}
+		clone := func(m model) model {
+			c := model{points: map[int]Vector3f{}, boxes: map[int]Box{}, nodes: map[int]*Node[int]{}}
+			for e, p := range m.points {
+				c.points[e] = p
+			}
+			for e, b := range m.boxes {
+				c.boxes[e] = b
+			}
+			for e, n := range m.nodes {
+				c.nodes[e] = n
+			}
+			return c
+		}
+
+		o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1}, opts...)
+		m := clone(model{})
+		var snapshot *Octree[int]
+		var frozen model
+
+		// points on a grid of eighths, some outside the tree
+		point := func(b []byte) Vector3f {
+			return Vector3f{float64(b[0]%20)/8 - 0.5, float64(b[1]%20)/8 - 0.5, float64(b[2]%20)/8 - 0.5}
+		}
+		pick := func(b byte) (int, bool) {
+			keys := make([]int, 0, len(m.nodes))
+			for e := range m.nodes {
+				keys = append(keys, e)
+			}
+			if len(keys) == 0 {
+				return 0, false
+			}
+			sort.Ints(keys)
+			return keys[int(b)%len(keys)], true
+		}
+		inBounds := func(p Vector3f) bool {
+			return growth || o.root.box.ContainsPoint(&p)
+		}
+
+		for i, ops := 0, data[1:]; len(ops) >= 4; i, ops = i+1, ops[4:] {
+			p := point(ops[1:4])
+			e, found := pick(ops[1])
+			_, isBox := m.boxes[e]
+
+			switch ops[0] % 8 {
+			case 0, 1:
+				node := o.Add(i, p)
+				equals(t, inBounds(p), node != nil)
+				if node != nil {
+					m.points[i] = p
+					m.nodes[i] = node
+				}
+			case 2:
+				if found && !isBox {
+					equals(t, true, o.RemoveUsing(e, m.nodes[e]))
+					delete(m.points, e)
+					delete(m.nodes, e)
+				}
+			case 3:
+				if found {
+					equals(t, true, o.Remove(e))
+					delete(m.points, e)
+					delete(m.boxes, e)
+					delete(m.nodes, e)
+				}
+			case 4:
+				if found && !isBox {
+					inside := inBounds(p)
+					node := o.Move(e, m.nodes[e], p)
+					equals(t, inside, node != nil)
+					if node != nil {
+						m.points[e] = p
+						m.nodes[e] = node
+					}
+				}
+			case 5:
+				size := Vector3f{float64(ops[1]%4) / 8, float64(ops[2]%4) / 8, float64(ops[3]%4) / 8}
+				box := Box{p, p.Plus(&size)}
+				node := o.AddBox(i, box)
+				equals(t, inBounds(box.min) && inBounds(box.max), node != nil)
+				if node != nil {
+					m.boxes[i] = box
+					m.nodes[i] = node
+				}
+			case 6:
+				if found && isBox {
+					box := Box{p, p.Plus(&Vector3f{0.125, 0.125, 0.125})}
+					inside := inBounds(box.min) && inBounds(box.max)
+					node := o.MoveBox(e, m.nodes[e], box)
+					equals(t, inside, node != nil)
+					if node != nil {
+						m.boxes[e] = box
+						m.nodes[e] = node
+					}
+				}
+			case 7:
+				snapshot = o.Snapshot()
+				frozen = clone(m)
+			}
+
+			if err := o.Validate(); err != nil {
+				t.Fatalf("after op %d: %v\n%v", i, err, o.ToString())
+			}
+		}
+
+		check := func(o *Octree[int], m model) {
+			equals(t, nil, o.Validate())
+
+			points := map[int]Vector3f{}
+			for p, e := range o.All() {
+				_, twice := points[e]
+				equals(t, false, twice)
+				points[e] = p
+			}
+			equals(t, m.points, points)
+
+			boxes := map[int]Box{}
+			for b, e := range o.Volumes() {
+				_, twice := boxes[e]
+				equals(t, false, twice)
+				boxes[e] = b
+			}
+			equals(t, m.boxes, boxes)
+		}
+
+		check(o, m)
+		if snapshot != nil {
+			check(snapshot, frozen)
+		}
+	})
+}
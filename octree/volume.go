@@ -0,0 +1,195 @@
+package octree
+
+// AddBox Inserts the element in the tree with the extent of the specified box,
+// rather than at a point. It is held by the smallest node that fully contains
+// the box (see WithLooseness for an alternative), and is retrieved by ElementsIn and ElementsWithin when the box
+// overlaps the query and by ElementsContaining. Returns nil if the box is not
+// within the tree, unless it was created using WithGrowth.
+func (o *Octree[T]) AddBox(element T, box Box) *Node[T] {
+	node, _ := o.AddBoxE(element, box)
+	return node
+}
+
+// AddBoxE Inserts the element in the tree with the extent of the specified box
+// as AddBox does, but returns a *PointError wrapping ErrInvalidPoint,
+// ErrOutOfBounds or ErrInternal when the element can't be added.
+func (o *Octree[T]) AddBoxE(element T, box Box) (*Node[T], error) {
+	box = NewBox(box.min, box.max)
+
+	for _, corner := range []*Vector3f{&box.min, &box.max} {
+		if err := checkPoint(corner); err != nil {
+			return nil, err
+		}
+	}
+
+	if o.growth {
+		if err := o.grow(&box.min, &box.max); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, corner := range []*Vector3f{&box.min, &box.max} {
+		if !o.root.box.ContainsPoint(corner) {
+			return nil, &PointError{Point: *corner, Err: ErrOutOfBounds}
+		}
+	}
+
+	node := o.writable(o.root).tryAddVolume(o, 0, element, &box)
+	if node == nil {
+		return nil, &PointError{Point: box.min, Err: ErrInternal}
+	}
+
+	return node, nil
+}
+
+// ElementsContaining Retrieves a slice of the elements added with AddBox
+// whose box contains the specified point.
+func (o *Octree[T]) ElementsContaining(point Vector3f) []T {
+	return o.root.elementsContaining(o, &point)
+}
+
+// MoveBox Moves the element added with AddBox to the extent of the specified
+// box, returning the node it is now held by. node should usually be the node
+// returned when this element was placed in the tree; the tree is only climbed
+// from there as far as the nearest node able to hold the new box, which in a
+// loose tree (see WithLooseness) is often the same node. Returns nil, leaving
+// the element where it was, when it isn't found or the new box is outside the
+// tree (and it was not created using WithGrowth).
+func (o *Octree[T]) MoveBox(element T, node *Node[T], box Box) *Node[T] {
+	box = NewBox(box.min, box.max)
+
+	if !o.root.box.ContainsPoint(&box.min) || !o.root.box.ContainsPoint(&box.max) {
+		// only grow once the element is known to be held,
+		// so that the tree is left unchanged when it isn't
+		if !o.growth || !o.holds(element, node, false, true) || o.grow(&box.min, &box.max) != nil {
+			return nil
+		}
+	}
+
+	node, region := o.resolve(node)
+	if node == nil {
+		return nil
+	}
+
+	removedFrom := o.remove(node, region, element, false, true)
+	if removedFrom == nil {
+		return nil
+	}
+
+	// climb to the nearest node that a search from the
+	// root for the box would pass through and could hold it
+	n := removedFrom
+	center := box.min.Lerp(&box.max, 0.5)
+	for n.parent != nil && (!o.owns(n, &center) || !o.fits(n, &box)) {
+		n = n.parent
+	}
+
+	moved := n.tryAddVolume(o, n.Depth(), element, &box)
+	o.collapse(removedFrom)
+
+	return moved.live()
+}
+
+// WithLooseness Makes the tree a loose octree, where the bounds of the
+// elements added with AddBox that each node can hold are its box scaled
+// about its center by factor (typically 2). Elements are placed by their
+// center and size, so that they don't pile up in the upper levels of the tree
+// when they straddle the boundaries between its octants, and rarely need to
+// be moved to another node when moved a short distance with MoveBox.
+// Defaults to 1; values less than 1 are treated as 1.
+func WithLooseness(factor float64) Option {
+	return func(opts *options) {
+		opts.looseness = factor
+	}
+}
+
+func (o *Octree[T]) looseBox(n *Node[T]) Box {
+	// the bounds of the volumes held by the node (or its descendants)
+	if o.looseness == 1 {
+		return n.box
+	}
+
+	center := n.box.min.Lerp(&n.box.max, 0.5)
+	half := n.box.Size()
+	half = half.Scale(o.looseness / 2)
+	return Box{min: center.Minus(&half), max: center.Plus(&half)}
+}
+
+func (o *Octree[T]) childFor(n *Node[T], box *Box) int {
+	// the index of the child that should hold the volume (or -1);
+	// the one containing its center, if the volume fits within it.
+	center := box.min.Lerp(&box.max, 0.5)
+	for i, child := range n.children {
+		if child.box.ContainsPoint(&center) {
+			if o.fits(child, box) {
+				return i
+			}
+			break
+		}
+	}
+
+	return -1
+}
+
+func (o *Octree[T]) fits(n *Node[T], box *Box) bool {
+	bounds := o.looseBox(n)
+	return bounds.Contains(box)
+}
+
+func (n *Node[T]) tryAddVolume(o *Octree[T], depth int, element T, box *Box) *Node[T] {
+	// attempt to add the element with the extent of the box in
+	// this node, or the smallest descendant that can hold it.
+
+	if !o.fits(n, box) {
+		return nil
+	}
+
+	if n.hasChildren {
+		if i := o.childFor(n, box); i >= 0 {
+			return o.ownChild(n, i).tryAddVolume(o, depth+1, element, box)
+		}
+
+		// straddles children, so held here
+	} else if n.load() >= o.capacity && o.canSubdivide(n, depth) && !n.holdsVolume(box) {
+		// subdivide because leaf is full, then try again as a branch;
+		// identical boxes are held together, like coincident points.
+		n.subdivide(o)
+		return n.tryAddVolume(o, depth, element, box)
+	}
+
+	n.volumes = append(n.volumes, volume[T]{box: *box, element: element})
+	n.adjustCount(1)
+
+	return n
+}
+
+func (n *Node[T]) holdsVolume(box *Box) bool {
+	for i := range n.volumes {
+		if n.volumes[i].box == *box {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (n *Node[T]) elementsContaining(o *Octree[T], point *Vector3f) []T {
+	// get any volume elements in this node (or a descendant)
+	// whose box contains the point. A point on a face between
+	// children may be within volumes held by both of them.
+
+	var elements []T
+	for i := range n.volumes {
+		if n.volumes[i].box.ContainsPoint(point) {
+			elements = append(elements, n.volumes[i].element)
+		}
+	}
+
+	for _, child := range n.children {
+		if bounds := o.looseBox(child); bounds.ContainsPoint(point) {
+			elements = append(elements, child.elementsContaining(o, point)...)
+		}
+	}
+
+	return elements
+}
@@ -0,0 +1,160 @@
+package octree
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestAddsVolumes(t *testing.T) {
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+
+	// held by the root until it subdivides
+	node1 := o.AddBox(1, Box{Vector3f{0.1, 0.1, 0.1}, Vector3f{0.2, 0.2, 0.2}})
+	equals(t, o.root, node1)
+	equals(t, 1, o.root.count)
+
+	// pushed into the octant containing it
+	node2 := o.AddBox(2, Box{Vector3f{0.4, 0.4, 0.4}, Vector3f{0.6, 0.6, 0.6}})
+	equals(t, o.root, node2)
+	equals(t, true, o.root.hasChildren)
+	equals(t, 1, len(o.root.children[0].volumes))
+	equals(t, 1, len(o.root.volumes))
+
+	// corners given in any order
+	node3 := o.AddBox(3, Box{Vector3f{0.9, 0.9, 0.6}, Vector3f{0.6, 0.6, 0.9}})
+	equals(t, o.root.children[7], node3)
+	equals(t, Box{Vector3f{0.6, 0.6, 0.6}, Vector3f{0.9, 0.9, 0.9}}, node3.volumes[0].box)
+
+	// shares leaves with points
+	o.Add(4, Vector3f{0.15, 0.15, 0.15})
+	o.Add(5, Vector3f{0.8, 0.8, 0.8})
+	equals(t, 5, o.root.count)
+	checkCounts(t, o.root)
+
+	equals(t, []int{1, 2, 4}, sorted(o.ElementsIn(Box{Vector3f{0, 0, 0}, Vector3f{0.45, 0.45, 0.45}})))
+	equals(t, []int{2, 3, 5}, sorted(o.ElementsIn(Box{Vector3f{0.55, 0.55, 0.55}, Vector3f{1, 1, 1}})))
+	equals(t, []int{1, 2, 3, 4, 5}, sorted(o.ElementsIn(Box{Vector3f{0, 0, 0}, Vector3f{1, 1, 1}})))
+	equals(t, []int{1, 2}, sorted(o.ElementsWithin(Vector3f{0.3, 0.3, 0.3}, 0.18)))
+
+	equals(t, []int{1}, o.ElementsContaining(Vector3f{0.15, 0.15, 0.15}))
+	equals(t, []int{2}, o.ElementsContaining(Vector3f{0.5, 0.5, 0.5}))
+	equals(t, []int{2, 3}, sorted(o.ElementsContaining(Vector3f{0.6, 0.6, 0.6})))
+	equals(t, 0, len(o.ElementsContaining(Vector3f{0.3, 0.3, 0.3})))
+
+	// points and volumes are kept apart
+	equals(t, []int{4}, o.ElementsAt(Vector3f{0.15, 0.15, 0.15}))
+	equals(t, 1, len(o.Nearest(Vector3f{0.5, 0.5, 0.5}, 1)))
+
+	equals(t, true, o.RemoveUsing(2, node2))
+	equals(t, false, o.RemoveUsing(2, node2))
+	equals(t, true, o.Remove(3))
+	equals(t, true, o.Remove(1))
+	checkCounts(t, o.root)
+	equals(t, 2, o.root.count)
+	equals(t, []int{4, 5}, sorted(o.ElementsIn(Box{Vector3f{0, 0, 0}, Vector3f{1, 1, 1}})))
+}
+
+func TestCollapsesVolumes(t *testing.T) {
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+
+	o.AddBox(1, Box{Vector3f{0.1, 0.1, 0.1}, Vector3f{0.2, 0.2, 0.2}})
+	o.AddBox(2, Box{Vector3f{0.3, 0.3, 0.3}, Vector3f{0.4, 0.4, 0.4}})
+	o.AddBox(3, Box{Vector3f{0.4, 0.4, 0.4}, Vector3f{0.6, 0.6, 0.6}})
+	equals(t, true, o.root.hasChildren)
+
+	equals(t, true, o.Remove(1))
+	equals(t, true, o.Remove(3))
+	equals(t, false, o.root.hasChildren)
+	equals(t, 1, len(o.root.volumes))
+	equals(t, []int{2}, o.ElementsContaining(Vector3f{0.35, 0.35, 0.35}))
+}
+
+func TestAddsIdenticalVolumes(t *testing.T) {
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+
+	// would otherwise subdivide until the boxes are too small to split
+	for i := 0; i < 3; i++ {
+		o.AddBox(i, Box{Vector3f{0.3, 0.3, 0.3}, Vector3f{0.3, 0.3, 0.3}})
+	}
+	equals(t, false, o.root.hasChildren)
+
+	o.AddBox(3, Box{Vector3f{0.7, 0.7, 0.7}, Vector3f{0.8, 0.8, 0.8}})
+	equals(t, 1, maxDepth(o.root))
+	equals(t, []int{0, 1, 2}, o.ElementsContaining(Vector3f{0.3, 0.3, 0.3}))
+}
+
+func TestAddBoxReportsErrors(t *testing.T) {
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+
+	_, err := o.AddBoxE(1, Box{Vector3f{0.5, 0.5, 0.5}, Vector3f{1.5, 0.5, 0.5}})
+	equals(t, true, errors.Is(err, ErrOutOfBounds))
+	equals(t, true, o.AddBox(1, Box{Vector3f{-0.5, 0.5, 0.5}, Vector3f{0.5, 0.5, 0.5}}) == nil)
+	equals(t, 0, o.root.count)
+
+	o = CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1}, WithGrowth())
+	_, err = o.AddBoxE(1, Box{Vector3f{-0.5, 0.5, 0.5}, Vector3f{1.5, 0.5, 0.5}})
+	equals(t, nil, err)
+	equals(t, []int{1}, o.ElementsContaining(Vector3f{1.25, 0.5, 0.5}))
+
+	// but not when either corner is invalid
+	bounds := o.Root().Bounds()
+	_, err = o.AddBoxE(2, Box{Vector3f{-1000, 0, 0}, Vector3f{math.Inf(1), 1, 1}})
+	equals(t, true, errors.Is(err, ErrInvalidPoint))
+	equals(t, bounds, o.Root().Bounds())
+}
+
+func TestVolumesMatchBruteForce(t *testing.T) {
+	r := rand.New(rand.NewSource(10))
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1}, WithCapacity(2))
+	boxes := make([]Box, 300)
+	nodes := make([]*Node[int], len(boxes))
+	for i := range boxes {
+		min := Vector3f{r.Float64(), r.Float64(), r.Float64()}
+		size := r.Float64() * 0.2
+		max := Vector3f{min[0] + size, min[1] + size, min[2] + size}
+		boxes[i] = Box{min, max.Min(&Vector3f{1, 1, 1})}
+		nodes[i] = o.AddBox(i, boxes[i])
+	}
+
+	// remove a third of them
+	for i := 0; i < len(boxes); i += 3 {
+		equals(t, true, o.RemoveUsing(i, nodes[i]))
+	}
+	checkCounts(t, o.root)
+
+	for q := 0; q < 20; q++ {
+		point := Vector3f{r.Float64(), r.Float64(), r.Float64()}
+		query := Box{point, Vector3f{point[0] + 0.1, point[1] + 0.1, point[2] + 0.1}}
+
+		containing := []int{}
+		overlapping := []int{}
+		within := []int{}
+		for i := range boxes {
+			if i%3 == 0 {
+				continue
+			}
+			if boxes[i].ContainsPoint(&point) {
+				containing = append(containing, i)
+			}
+			if boxes[i].Intersects(&query) {
+				overlapping = append(overlapping, i)
+			}
+			if boxes[i].DistanceToPoint(&point) <= 0.1 {
+				within = append(within, i)
+			}
+		}
+
+		equals(t, containing, sorted(o.ElementsContaining(point)))
+		equals(t, overlapping, sorted(o.ElementsIn(query)))
+		equals(t, within, sorted(o.ElementsWithin(point, 0.1)))
+	}
+}
+
+func sorted(elements []int) []int {
+	s := append([]int{}, elements...)
+	sort.Ints(s)
+	return s
+}
@@ -0,0 +1,58 @@
+package octree
+
+// ElementsWithin Retrieves a slice of elements that exist within the
+// sphere described by the specified center and radius, including those
+// added with AddBox whose box overlaps the sphere, or nil if there are none.
+func (o *Octree[T]) ElementsWithin(center Vector3f, radius float64) []T {
+	if radius < 0 {
+		return nil
+	}
+
+	return o.root.elementsWithin(o, &center, radius, o.fanOut(), nil)
+}
+
+func (n *Node[T]) elementsWithin(o *Octree[T], center *Vector3f, radius float64, f *fanOut, elements []T) []T {
+	// append any elements in this node (or a descendant)
+	// within the specified sphere to elements
+
+	for i := range n.volumes {
+		if n.volumes[i].box.DistanceToPoint(center) <= radius {
+			elements = append(elements, n.volumes[i].element)
+		}
+	}
+
+	if n.hasChildren {
+		if f.splits(n.count) {
+			return fanOutChildren(f, n, elements, func(child *Node[T], elements []T) []T {
+				return child.childElementsWithin(o, center, radius, f, elements)
+			})
+		}
+
+		for _, child := range n.children {
+			elements = child.childElementsWithin(o, center, radius, f, elements)
+		}
+		return elements
+	}
+
+	// when a leaf
+	for i := range n.entries {
+		if n.entries[i].point.Distance(center) <= radius {
+			elements = append(elements, n.entries[i].elements...)
+		}
+	}
+
+	return elements
+}
+
+func (n *Node[T]) childElementsWithin(o *Octree[T], center *Vector3f, radius float64, f *fanOut, elements []T) []T {
+	// append any elements within the sphere in this child of a branch being searched
+	if n.box.MaxDistanceToPoint(center) <= radius {
+		// fully contained
+		return n.appendAll(f, elements)
+	} else if bounds := o.looseBox(n); bounds.DistanceToPoint(center) <= radius {
+		// partially contained
+		return n.elementsWithin(o, center, radius, f, elements)
+	}
+
+	return elements
+}
@@ -0,0 +1,54 @@
+package octree
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestRetrievesElementsWithin(t *testing.T) {
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+
+	o.Add(1, Vector3f{0.5, 0.5, 0.5})
+	equals(t, []int{1}, o.ElementsWithin(Vector3f{0.5, 0.5, 0.5}, 0))
+	equals(t, 0, len(o.ElementsWithin(Vector3f{0.5, 0.5, 0.5}, -1)))
+
+	o.Add(2, Vector3f{0.5, 0.5, 0.5})
+	o.Add(3, Vector3f{0.75, 0.75, 0.75})
+	o.Add(4, Vector3f{0.1, 0.1, 0.1})
+
+	// inside the bounding cube of the sphere, but not the sphere
+	equals(t, []int{1, 2}, o.ElementsWithin(Vector3f{0.5, 0.5, 0.5}, 0.3))
+	// on the surface of the sphere
+	equals(t, []int{3}, o.ElementsWithin(Vector3f{0.75, 0.75, 1}, 0.25))
+	// sphere contains whole tree
+	equals(t, 4, len(o.ElementsWithin(Vector3f{0.5, 0.5, 0.5}, 1)))
+	// sphere outside tree
+	equals(t, []int(nil), o.ElementsWithin(Vector3f{3, 3, 3}, 1))
+}
+
+func TestElementsWithinMatchesBruteForce(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	o := CreateOctree[int](Vector3f{0, 0, 0}, Vector3f{1, 1, 1})
+	points := make([]Vector3f, 500)
+	for i := range points {
+		points[i] = Vector3f{r.Float64(), r.Float64(), r.Float64()}
+		o.Add(i, points[i])
+	}
+
+	for q := 0; q < 20; q++ {
+		center := Vector3f{r.Float64(), r.Float64(), r.Float64()}
+		radius := r.Float64() * 0.5
+
+		var exp []int
+		for i := range points {
+			if points[i].Distance(&center) <= radius {
+				exp = append(exp, i)
+			}
+		}
+
+		act := o.ElementsWithin(center, radius)
+		sort.Ints(act)
+		equals(t, exp, act)
+	}
+}
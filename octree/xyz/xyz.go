@@ -0,0 +1,296 @@
+// Package xyz Reads point clouds from delimited text files, such as XYZ and
+// CSV dumps, into octrees, and writes points back out.
+package xyz
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bjnsn/go-octree/octree"
+	"github.com/bjnsn/go-octree/octree/internal/cloud"
+)
+
+// ErrFormat The data read doesn't hold a point on each line, or can't be read.
+var ErrFormat = errors.New("xyz: invalid format")
+
+// Header Describes the columns of a delimited text file.
+type Header struct {
+	// Delimiter separates the values on each line; e.g. ',' for CSV, or ' '
+	// for values separated by any run of spaces and tabs, as in XYZ files.
+	Delimiter rune
+	// Names is whether the first line names the columns, rather than
+	// holding a point.
+	Names bool
+	// Columns are the names of the columns other than x, y and z, in the
+	// order of the Values of points; empty when the file doesn't name them.
+	Columns []string
+}
+
+// Point The position of a point, and the values of the other columns of
+// its line in the order of the Columns of the header of its file; e.g. its
+// color or intensity. Write gives each point a line of its own again, with
+// the columns of the header.
+type Point struct {
+	Position octree.Vector3f
+	Values   []float64
+}
+
+// Reader Reads the points of a delimited text file a line at a time,
+// skipping blank lines and comments.
+type Reader struct {
+	r      *bufio.Reader
+	header *Header
+	// axes holds the column of x, y and z, and values
+	// the index in Point.Values of each column, or -1.
+	axes    [3]int
+	values  []int
+	pending []string
+	line    int
+}
+
+// NewReader Reads the first line of the file read from r that isn't blank
+// or a comment (starting with # or //), detecting its delimiter, and whether
+// it names the columns; it does unless each of its values is a number. The
+// position of points is taken from the columns named x, y and z, ignoring
+// case, or the first three columns. Returns an error wrapping ErrFormat if
+// the file has no such line, or it has fewer than three columns.
+func NewReader(r io.Reader) (*Reader, error) {
+	reader := &Reader{r: bufio.NewReader(r), header: &Header{}, axes: [3]int{0, 1, 2}}
+
+	line, err := reader.readLine()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: no points", ErrFormat)
+	} else if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case strings.ContainsRune(line, ','):
+		reader.header.Delimiter = ','
+	case strings.ContainsRune(line, ';'):
+		reader.header.Delimiter = ';'
+	default:
+		reader.header.Delimiter = ' '
+	}
+
+	fields := reader.split(line)
+	if len(fields) < 3 {
+		return nil, formatError(reader.line, "has %d columns, rather than at least 3", len(fields))
+	}
+
+	for _, field := range fields {
+		if _, err := strconv.ParseFloat(field, 64); err != nil {
+			reader.header.Names = true
+			break
+		}
+	}
+
+	names := make([]string, len(fields))
+	if reader.header.Names {
+		for i, field := range fields {
+			names[i] = strings.Trim(field, `"'`)
+		}
+
+		found := [3]int{-1, -1, -1}
+		for i, name := range names {
+			axis := strings.Index("xyz", strings.ToLower(name))
+			if len(name) == 1 && axis >= 0 && found[axis] < 0 {
+				found[axis] = i
+			}
+		}
+		if found[0] >= 0 && found[1] >= 0 && found[2] >= 0 {
+			reader.axes = found
+		}
+	} else {
+		reader.pending = fields
+	}
+
+	reader.values = make([]int, len(fields))
+	for i := range fields {
+		reader.values[i] = -1
+		if i != reader.axes[0] && i != reader.axes[1] && i != reader.axes[2] {
+			reader.values[i] = len(reader.header.Columns)
+			reader.header.Columns = append(reader.header.Columns, names[i])
+		}
+	}
+
+	return reader, nil
+}
+
+// Header Returns the header of the file, describing its columns.
+func (r *Reader) Header() *Header {
+	return r.header
+}
+
+// Read Reads the point on the next line, returning io.EOF after the last one,
+// or an error wrapping ErrFormat if the line doesn't have a number in each
+// of the columns of the first.
+func (r *Reader) Read() (Point, error) {
+	fields := r.pending
+	r.pending = nil
+	if fields == nil {
+		line, err := r.readLine()
+		if err != nil {
+			return Point{}, err
+		}
+		fields = r.split(line)
+	}
+
+	if len(fields) != len(r.values) {
+		return Point{}, formatError(r.line, "has %d columns, rather than %d", len(fields), len(r.values))
+	}
+
+	point := Point{Values: make([]float64, len(r.header.Columns))}
+	for i, field := range fields {
+		value, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return Point{}, formatError(r.line, "has %q, rather than a number, in column %d", field, i+1)
+		}
+
+		if r.values[i] >= 0 {
+			point.Values[r.values[i]] = value
+		}
+	}
+	for i, axis := range r.axes {
+		point.Position[i], _ = strconv.ParseFloat(fields[axis], 64)
+	}
+
+	return point, nil
+}
+
+// ReadInto Streams the points of the file read from r into the tree,
+// returning the header of the file. Returns an error wrapping ErrFormat,
+// giving the line, if the file can't be read; a point that can't be added
+// stops the read with its *octree.PointError, also giving the line, once
+// the points on the lines above it have been added.
+func ReadInto(r io.Reader, o *octree.Octree[Point]) (*Header, error) {
+	reader, err := NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cloud.ReadInto(o, reader.next, reader.where); err != nil {
+		return nil, err
+	}
+	return reader.Header(), nil
+}
+
+// Load Reads every line of the file read from r before building a tree
+// fitted to the box around the points with octree.BuildOctree, which is
+// several times faster than adding them one at a time with ReadInto. The tree
+// is returned along with the header of the file.
+func Load(r io.Reader, opts ...octree.Option) (*octree.Octree[Point], *Header, error) {
+	reader, err := NewReader(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	o, err := cloud.Build(reader.next, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return o, reader.Header(), nil
+}
+
+func (r *Reader) next() (Point, octree.Vector3f, error) {
+	point, err := r.Read()
+	return point, point.Position, err
+}
+
+func (r *Reader) where() string {
+	return fmt.Sprintf("xyz: adding point on line %d", r.line)
+}
+
+// Write Writes the points to w, a line each, with the delimiter of the
+// header, after a line naming the columns if it has Names. The position of
+// each point is written first, followed by its values. Returns an error if a
+// point doesn't have a value for each column.
+func Write(w io.Writer, header *Header, points []Point) error {
+	delimiter := header.Delimiter
+	if delimiter == 0 {
+		delimiter = ' '
+	}
+	if !utf8.ValidRune(delimiter) || strings.ContainsRune("0123456789+-.eE\r\n", delimiter) {
+		return fmt.Errorf("xyz: invalid delimiter %q", delimiter)
+	}
+	separator := string(delimiter)
+
+	bw := bufio.NewWriter(w)
+	if header.Names {
+		names := append([]string{"x", "y", "z"}, header.Columns...)
+		for _, name := range names {
+			if name == "" || strings.ContainsAny(name, separator+" \t\r\n") {
+				return fmt.Errorf("xyz: invalid column name %q", name)
+			}
+		}
+		if _, err := io.WriteString(bw, strings.Join(names, separator)+"\n"); err != nil {
+			return err
+		}
+	}
+
+	var buf []byte
+	for i := range points {
+		point := &points[i]
+		if len(point.Values) != len(header.Columns) {
+			return fmt.Errorf("xyz: point %d has %d values for %d columns", i, len(point.Values), len(header.Columns))
+		}
+
+		buf = buf[:0]
+		for j := 0; j < 3+len(point.Values); j++ {
+			if j > 0 {
+				buf = append(buf, separator...)
+			}
+			if j < 3 {
+				buf = strconv.AppendFloat(buf, point.Position[j], 'g', -1, 64)
+			} else {
+				buf = strconv.AppendFloat(buf, point.Values[j-3], 'g', -1, 64)
+			}
+		}
+		buf = append(buf, '\n')
+
+		if _, err := bw.Write(buf); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+func formatError(line int, format string, args ...interface{}) error {
+	return fmt.Errorf("%w: line %d %v", ErrFormat, line, fmt.Sprintf(format, args...))
+}
+
+func (r *Reader) readLine() (string, error) {
+	// read the next line that isn't blank or a comment
+	for {
+		line, err := r.r.ReadString('\n')
+		if err == io.EOF && line == "" {
+			return "", io.EOF
+		} else if err != nil && err != io.EOF {
+			return "", err
+		}
+		r.line++
+
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "//") {
+			return line, nil
+		}
+	}
+}
+
+func (r *Reader) split(line string) []string {
+	if r.header.Delimiter == ' ' {
+		return strings.Fields(line)
+	}
+
+	fields := strings.Split(line, string(r.header.Delimiter))
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+	return fields
+}
@@ -0,0 +1,136 @@
+package xyz
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/bjnsn/go-octree/octree"
+	"github.com/bjnsn/go-octree/octree/internal/testutil"
+)
+
+var equals = testutil.Equals
+
+const csv = `# exported scan
+intensity,X,Y,Z
+0.5, 0, 0, 0
+1, 1, 0, 0
+
+0.75, 0.25, 1, 2.5
+`
+
+const plain = "0 0 0 255\n1\t0  0 128\r\n0.25 1 2.5 0\n"
+
+func TestReads(t *testing.T) {
+	reader, err := NewReader(strings.NewReader(csv))
+	equals(t, nil, err)
+	equals(t, &Header{Delimiter: ',', Names: true, Columns: []string{"intensity"}}, reader.Header())
+
+	point, err := reader.Read()
+	equals(t, nil, err)
+	equals(t, Point{octree.Vector3f{0, 0, 0}, []float64{0.5}}, point)
+
+	for i := 0; i < 2; i++ {
+		_, err = reader.Read()
+		equals(t, nil, err)
+	}
+	_, err = reader.Read()
+	equals(t, io.EOF, err)
+
+	reader, err = NewReader(strings.NewReader(plain))
+	equals(t, nil, err)
+	equals(t, &Header{Delimiter: ' ', Columns: []string{""}}, reader.Header())
+
+	point, err = reader.Read()
+	equals(t, nil, err)
+	equals(t, Point{octree.Vector3f{0, 0, 0}, []float64{255}}, point)
+	point, err = reader.Read()
+	equals(t, nil, err)
+	equals(t, Point{octree.Vector3f{1, 0, 0}, []float64{128}}, point)
+
+	// into a tree bounded by the box around the points
+	for _, data := range []string{csv, plain} {
+		o, _, err := Load(strings.NewReader(data))
+		equals(t, nil, err)
+		equals(t, 3, o.Stats().Elements)
+		equals(t, octree.NewBox(octree.Vector3f{0, 0, 0}, octree.Vector3f{1, 1, 2.5}), o.Root().Bounds())
+		equals(t, nil, o.Validate())
+	}
+}
+
+func TestRoundTrips(t *testing.T) {
+	points := []Point{
+		{octree.Vector3f{0.1, -2, 3e10}, []float64{1, 0.5}},
+		{octree.Vector3f{1.0 / 3, 0, 0}, []float64{255, -1.5}},
+	}
+
+	for _, header := range []*Header{
+		{Delimiter: ',', Names: true, Columns: []string{"red", "intensity"}},
+		{Delimiter: ';', Names: true, Columns: []string{"red", "intensity"}},
+		{Delimiter: ' ', Columns: []string{"", ""}},
+		{Delimiter: '\t', Names: true, Columns: []string{"red", "intensity"}},
+	} {
+		var buf bytes.Buffer
+		equals(t, nil, Write(&buf, header, points))
+
+		reader, err := NewReader(&buf)
+		equals(t, nil, err)
+		if header.Delimiter == '\t' {
+			// read as any whitespace
+			header.Delimiter = ' '
+		}
+		equals(t, header, reader.Header())
+		for i := range points {
+			point, err := reader.Read()
+			equals(t, nil, err)
+			equals(t, points[i], point)
+		}
+		_, err = reader.Read()
+		equals(t, io.EOF, err)
+	}
+}
+
+func TestWritesQueryResults(t *testing.T) {
+	o := octree.CreateOctree[Point](octree.Vector3f{0, 0, 0}, octree.Vector3f{1, 1, 3})
+	header, err := ReadInto(strings.NewReader(csv), o)
+	equals(t, nil, err)
+
+	found := o.ElementsIn(octree.NewBox(octree.Vector3f{0, 0, 0}, octree.Vector3f{1, 0.5, 0.5}))
+	var buf bytes.Buffer
+	equals(t, nil, Write(&buf, header, found))
+	equals(t, "x,y,z,intensity\n0,0,0,0.5\n1,0,0,1\n", buf.String())
+}
+
+func TestReportsErrors(t *testing.T) {
+	for _, data := range []string{
+		"",
+		"# nothing\n\n",
+		"0,0\n",
+		"x y\n",
+	} {
+		_, err := NewReader(strings.NewReader(data))
+		equals(t, true, errors.Is(err, ErrFormat))
+	}
+
+	for _, data := range []string{
+		"0 0 0\n0 0\n",
+		"0 0 0\n0 0 0 0\n",
+		"0 0 0\n0 0 a\n",
+		"x,y,z\n0,0,\n",
+	} {
+		_, _, err := Load(strings.NewReader(data))
+		equals(t, true, errors.Is(err, ErrFormat))
+	}
+
+	_, _, err := Load(strings.NewReader("x,y,z\n"))
+	equals(t, octree.ErrNoPoints, err)
+
+	o := octree.CreateOctree[Point](octree.Vector3f{0, 0, 0}, octree.Vector3f{0.5, 0.5, 0.5})
+	_, err = ReadInto(strings.NewReader(csv), o)
+	equals(t, true, errors.Is(err, octree.ErrOutOfBounds))
+
+	equals(t, false, Write(io.Discard, &Header{Delimiter: '.'}, nil) == nil)
+	equals(t, false, Write(io.Discard, &Header{Columns: []string{"a"}}, []Point{{}}) == nil)
+}